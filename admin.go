@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/moderation"
+)
+
+type SetRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// middlewareRequireRole only lets through callers with at least the min role.
+// Callers authenticate with their access token, whose role claim is checked
+// against the database so a demotion takes effect at once, or with
+// "Authorization: ApiKey <ADMIN_API_KEY>", which counts as an admin.
+func (cfg *apiConfig) middlewareRequireRole(min auth.Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Authorization"), "ApiKey ") {
+			key, err := auth.GetAPIKey(r.Header)
+			if err != nil {
+				respondWithError(w, http.StatusUnauthorized, "Missing admin API key", err)
+				return
+			}
+			if cfg.adminAPIKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(cfg.adminAPIKey)) != 1 {
+				respondWithError(w, http.StatusUnauthorized, "Invalid admin API key", nil)
+				return
+			}
+			next(w, r)
+			return
+		}
+
+		tokenString, err := auth.GetBearerToken(r.Header)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "Missing token", err)
+			return
+		}
+
+		userID, role, err := auth.ParseJWT(tokenString, cfg.jwtKeys)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+			return
+		}
+		if !role.AtLeast(min) {
+			respondWithError(w, http.StatusForbidden, "Forbidden", nil)
+			return
+		}
+
+		user, err := cfg.dbQueries.GetUserByID(r.Context(), userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "DB error", err)
+			return
+		}
+		if !auth.Role(user.Role).AtLeast(min) {
+			respondWithError(w, http.StatusForbidden, "Forbidden", nil)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// moderatorID returns the user behind a request that passed
+// middlewareRequireRole. Actions that go in the moderation log need one,
+// so the admin API key, which belongs to nobody, can't take them.
+func (cfg *apiConfig) moderatorID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	actorID, ok := cfg.viewerID(r)
+	if !ok {
+		respondWithError(w, http.StatusForbidden, "Moderation actions need a moderator's access token", nil)
+	}
+	return actorID, ok
+}
+
+// bootstrapAdmin promotes the user named by BOOTSTRAP_ADMIN_EMAIL to admin
+// when they log in, as long as they have verified their email and there are
+// no admins yet. It is how the first admin gets created; after that, admins
+// grant roles through the API.
+func (cfg *apiConfig) bootstrapAdmin(ctx context.Context, user database.User) (database.User, error) {
+	if cfg.bootstrapAdminEmail == "" || !strings.EqualFold(user.Email, cfg.bootstrapAdminEmail) || user.Role == string(auth.RoleAdmin) {
+		return user, nil
+	}
+
+	// The check for an existing admin happens in the same statement, so
+	// there is never a window for a second one
+	promoted, err := cfg.dbQueries.PromoteFirstAdmin(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user, nil
+		}
+		return user, err
+	}
+	return promoted, nil
+}
+
+// handlerSetUserRole changes a user's role. The new role shows up in their
+// access token the next time they log in or refresh.
+func (cfg *apiConfig) handlerSetUserRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	var params SetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	role, err := auth.ParseRole(params.Role)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid role", err)
+		return
+	}
+
+	actorID, ok := cfg.moderatorID(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	user, err := qtx.SetUserRole(r.Context(), database.SetUserRoleParams{
+		ID:   userID,
+		Role: string(role),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "User not found", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't set role", err)
+		return
+	}
+
+	_, err = qtx.CreateModerationAction(r.Context(), database.CreateModerationActionParams{
+		UserID:  uuid.NullUUID{UUID: userID, Valid: true},
+		ActorID: uuid.NullUUID{UUID: actorID, Valid: true},
+		Action:  string(moderation.ActionSetRole),
+		Note:    string(role),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record moderation action", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't set role", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, userResponse(user))
+}
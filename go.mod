@@ -2,14 +2,18 @@ module github.com/SaadVSP96/Chirpy_Server.git
 
 go 1.25.4
 
-require github.com/google/uuid v1.6.0
+require (
+	github.com/alexedwards/argon2id v1.0.0
+	github.com/golang-jwt/jwt/v5 v5.3.0
+	github.com/google/uuid v1.6.0
+	github.com/joho/godotenv v1.5.1
+	github.com/lib/pq v1.10.9
+	github.com/rivo/uniseg v0.4.7
+	golang.org/x/text v0.14.0
+)
 
 require (
-	github.com/alexedwards/argon2id v1.0.0 // indirect
 	github.com/go-chi/chi v1.5.5 // indirect
-	github.com/golang-jwt/jwt/v5 v5.3.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
-	github.com/lib/pq v1.10.9 // indirect
 	golang.org/x/crypto v0.14.0 // indirect
 	golang.org/x/sys v0.13.0 // indirect
 )
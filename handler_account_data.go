@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/account"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+)
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type DeleteAccountResponse struct {
+	Status      string `json:"status"`
+	DeleteAfter string `json:"delete_after"`
+}
+
+type ExportSubscription struct {
+	IsChirpyRed bool `json:"is_chirpy_red"`
+}
+
+type ExportSession struct {
+	CreatedAt string `json:"created_at"`
+	ExpiresAt string `json:"expires_at"`
+	RevokedAt string `json:"revoked_at,omitempty"`
+}
+
+type ExportLike struct {
+	ChirpID string `json:"chirp_id"`
+	LikedAt string `json:"liked_at"`
+}
+
+// exportPageSize is how many rows the export reads from a table at a time.
+const exportPageSize = 500
+
+// handlerExportAccount streams everything stored about the caller as one JSON
+// document: profile, subscription, sessions, filters, and then every chirp
+// they wrote along with its earlier bodies, like, follow in either direction,
+// block, mute, notification and report they filed. Refresh tokens themselves
+// are left out; they are credentials, not data.
+func (cfg *apiConfig) handlerExportAccount(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+	tokens, err := cfg.dbQueries.ListRefreshTokensByUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get sessions", err)
+		return
+	}
+	filters, err := cfg.getUserFilters(r, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get filters", err)
+		return
+	}
+
+	sessions := make([]ExportSession, 0, len(tokens))
+	for _, t := range tokens {
+		s := ExportSession{
+			CreatedAt: t.CreatedAt.Format(time.RFC3339),
+			ExpiresAt: t.ExpiresAt.Format(time.RFC3339),
+		}
+		if t.RevokedAt.Valid {
+			s.RevokedAt = t.RevokedAt.Time.Format(time.RFC3339)
+		}
+		sessions = append(sessions, s)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="chirpy-export.json"`)
+	w.WriteHeader(http.StatusOK)
+
+	// The lists are read and written a page at a time, so a long history is
+	// never held in memory. The status line has already gone out by the time
+	// one fails, so an error drops the connection instead of finishing the
+	// document, and a finished one ends with "complete": true.
+	ctx := r.Context()
+	e := &exportWriter{w: w, enc: json.NewEncoder(w)}
+	e.field("{", "exported_at", time.Now().UTC().Format(time.RFC3339))
+	e.field(",", "profile", userResponse(user))
+	e.field(",", "subscription", ExportSubscription{IsChirpyRed: user.IsChirpyRed})
+	e.field(",", "sessions", sessions)
+	e.field(",", "filters", userFiltersResponse(filters))
+
+	exportList(e, "chirps", func(at sql.NullTime, id uuid.NullUUID) ([]database.Chirp, error) {
+		return cfg.dbQueries.ListChirpsByUserPage(ctx, database.ListChirpsByUserPageParams{UserID: userID, CursorCreatedAt: at, CursorID: id, Limit: exportPageSize})
+	}, func(c database.Chirp) (time.Time, uuid.UUID, any) {
+		return c.CreatedAt, c.ID, chirpResponse(c)
+	})
+	exportList(e, "revisions", func(at sql.NullTime, id uuid.NullUUID) ([]database.ChirpRevision, error) {
+		return cfg.dbQueries.ListRevisionsByUserPage(ctx, database.ListRevisionsByUserPageParams{UserID: userID, CursorReplacedAt: at, CursorID: id, Limit: exportPageSize})
+	}, func(rev database.ChirpRevision) (time.Time, uuid.UUID, any) {
+		return rev.ReplacedAt, rev.ID, chirpRevisionResponse(rev)
+	})
+	exportList(e, "likes", func(at sql.NullTime, id uuid.NullUUID) ([]database.ListLikesByUserPageRow, error) {
+		return cfg.dbQueries.ListLikesByUserPage(ctx, database.ListLikesByUserPageParams{UserID: userID, CursorCreatedAt: at, CursorID: id, Limit: exportPageSize})
+	}, func(l database.ListLikesByUserPageRow) (time.Time, uuid.UUID, any) {
+		return l.CreatedAt, l.ChirpID, ExportLike{ChirpID: l.ChirpID.String(), LikedAt: l.CreatedAt.Format(time.RFC3339)}
+	})
+	exportList(e, "following", func(at sql.NullTime, id uuid.NullUUID) ([]database.ListFollowingPageRow, error) {
+		return cfg.dbQueries.ListFollowingPage(ctx, database.ListFollowingPageParams{UserID: userID, CursorCreatedAt: at, CursorID: id, Limit: exportPageSize})
+	}, func(f database.ListFollowingPageRow) (time.Time, uuid.UUID, any) {
+		return f.CreatedAt, f.UserID, FollowResponse{UserID: f.UserID.String(), FollowedAt: f.CreatedAt.Format(time.RFC3339)}
+	})
+	exportList(e, "followers", func(at sql.NullTime, id uuid.NullUUID) ([]database.ListFollowersPageRow, error) {
+		return cfg.dbQueries.ListFollowersPage(ctx, database.ListFollowersPageParams{UserID: userID, CursorCreatedAt: at, CursorID: id, Limit: exportPageSize})
+	}, func(f database.ListFollowersPageRow) (time.Time, uuid.UUID, any) {
+		return f.CreatedAt, f.UserID, FollowResponse{UserID: f.UserID.String(), FollowedAt: f.CreatedAt.Format(time.RFC3339)}
+	})
+	exportList(e, "blocks", func(at sql.NullTime, id uuid.NullUUID) ([]database.ListBlocksPageRow, error) {
+		return cfg.dbQueries.ListBlocksPage(ctx, database.ListBlocksPageParams{UserID: userID, CursorCreatedAt: at, CursorID: id, Limit: exportPageSize})
+	}, func(b database.ListBlocksPageRow) (time.Time, uuid.UUID, any) {
+		return b.CreatedAt, b.UserID, RelationshipResponse{UserID: b.UserID.String(), CreatedAt: b.CreatedAt.Format(time.RFC3339)}
+	})
+	exportList(e, "mutes", func(at sql.NullTime, id uuid.NullUUID) ([]database.ListMutesPageRow, error) {
+		return cfg.dbQueries.ListMutesPage(ctx, database.ListMutesPageParams{UserID: userID, CursorCreatedAt: at, CursorID: id, Limit: exportPageSize})
+	}, func(m database.ListMutesPageRow) (time.Time, uuid.UUID, any) {
+		return m.CreatedAt, m.UserID, RelationshipResponse{UserID: m.UserID.String(), CreatedAt: m.CreatedAt.Format(time.RFC3339)}
+	})
+	exportList(e, "notifications", func(at sql.NullTime, id uuid.NullUUID) ([]database.Notification, error) {
+		return cfg.dbQueries.ListAllNotificationsPage(ctx, database.ListAllNotificationsPageParams{UserID: userID, CursorCreatedAt: at, CursorID: id, Limit: exportPageSize})
+	}, func(n database.Notification) (time.Time, uuid.UUID, any) {
+		return n.CreatedAt, n.ID, notificationResponse(n)
+	})
+	exportList(e, "reports", func(at sql.NullTime, id uuid.NullUUID) ([]database.ChirpReport, error) {
+		return cfg.dbQueries.ListReportsByReporterPage(ctx, database.ListReportsByReporterPageParams{UserID: userID, CursorCreatedAt: at, CursorID: id, Limit: exportPageSize})
+	}, func(rep database.ChirpReport) (time.Time, uuid.UUID, any) {
+		return rep.CreatedAt, rep.ID, reportResponse(rep)
+	})
+	e.field(",", "complete", true)
+	e.raw("}\n")
+
+	if e.err != nil {
+		log.Printf("Error writing export: %v", e.err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// exportWriter writes the export document piece by piece. After the first
+// error it writes nothing more and keeps the error in err.
+type exportWriter struct {
+	w   io.Writer
+	enc *json.Encoder
+	err error
+}
+
+func (e *exportWriter) raw(s string) {
+	if e.err == nil {
+		_, e.err = io.WriteString(e.w, s)
+	}
+}
+
+func (e *exportWriter) value(v any) {
+	if e.err == nil {
+		e.err = e.enc.Encode(v)
+	}
+}
+
+// field writes sep followed by one "name": value member.
+func (e *exportWriter) field(sep, name string, v any) {
+	e.raw(sep + `"` + name + `":`)
+	e.value(v)
+}
+
+// exportList writes a "name": [...] member, reading it exportPageSize rows at
+// a time with fetch. fetch gets the keyset position of the last row it
+// returned, and item gives each row's position along with what to write for
+// it.
+func exportList[R any](e *exportWriter, name string, fetch func(sql.NullTime, uuid.NullUUID) ([]R, error), item func(R) (time.Time, uuid.UUID, any)) {
+	e.raw(`,"` + name + `":[`)
+	var at sql.NullTime
+	var id uuid.NullUUID
+	first := true
+	for e.err == nil {
+		rows, err := fetch(at, id)
+		if err != nil {
+			e.err = err
+			return
+		}
+		for _, row := range rows {
+			createdAt, rowID, v := item(row)
+			if !first {
+				e.raw(",")
+			}
+			e.value(v)
+			first = false
+			at = sql.NullTime{Time: createdAt, Valid: true}
+			id = uuid.NullUUID{UUID: rowID, Valid: true}
+		}
+		if len(rows) < exportPageSize {
+			break
+		}
+	}
+	e.raw("]")
+}
+
+// handlerDeleteAccount schedules the caller's account for deletion once the
+// grace period is over. Until then it behaves like a deactivated account and
+// can be restored through /api/users/me/reactivate.
+func (cfg *apiConfig) handlerDeleteAccount(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	var req DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON", err)
+		return
+	}
+	if req.Password == "" {
+		respondWithError(w, http.StatusBadRequest, "Password is required", nil)
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+	ok, _ := auth.CheckPasswordHash(req.Password, user.HashedPassword)
+	if !ok {
+		respondWithError(w, http.StatusForbidden, "Incorrect password", nil)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	deleteAfter := time.Now().UTC().Add(cfg.deletionGrace)
+	rows, err := qtx.ScheduleUserDeletion(r.Context(), database.ScheduleUserDeletionParams{
+		ID:          userID,
+		DeleteAfter: sql.NullTime{Time: deleteAfter, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete account", err)
+		return
+	}
+	if rows == 0 {
+		respondWithError(w, http.StatusConflict, "Account is not active", nil)
+		return
+	}
+
+	// Sign the account out everywhere
+	if err := qtx.RevokeUserRefreshTokens(r.Context(), userID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete account", err)
+		return
+	}
+	cfg.authUsers.Forget(userID)
+	respondWithJSON(w, http.StatusAccepted, DeleteAccountResponse{
+		Status:      string(account.StatusDeleted),
+		DeleteAfter: deleteAfter.Format(time.RFC3339),
+	})
+}
+
+// runAccountPurge removes accounts whose deletion grace period has run out,
+// immediately and then every interval until ctx is done.
+func (cfg *apiConfig) runAccountPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		now := sql.NullTime{Time: time.Now().UTC(), Valid: true}
+		if n, err := cfg.dbQueries.DeleteUsersPastGracePeriod(ctx, now); err != nil {
+			log.Printf("Failed to purge deleted accounts: %v", err)
+		} else if n > 0 {
+			log.Printf("Purged %d deleted accounts", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/account"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/moderation"
+)
+
+type ReactivateRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type SuspendUserRequest struct {
+	Reason string `json:"reason"`
+	// Duration is a Go duration such as "72h"; empty means indefinitely.
+	Duration string `json:"duration"`
+}
+
+// checkAccount returns nil if the user's account may use the API right now.
+func checkAccount(user database.User) error {
+	return account.Check(user.Status, user.SuspensionReason, user.SuspendedUntil, time.Now().UTC())
+}
+
+// middlewareAuthenticate refuses every request made with an access token that
+// has been revoked, or that belongs to an account that isn't active, so no
+// handler has to check for itself. A token is revoked once the user's token
+// version moves past the one it carries, or once its session is signed out.
+// Users and sessions are looked up through short-lived caches, so the common
+// case costs no database round trip. Requests without a valid access token
+// pass through untouched; the handlers deal with those.
+func (cfg *apiConfig) middlewareAuthenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenString, err := auth.GetBearerToken(r.Header)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		claims, err := auth.ParseClaims(tokenString, cfg.jwtKeys)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		userID, _ := claims.UserID()
+
+		user, err := cfg.authUsers.Get(r.Context(), userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "DB error", err)
+			return
+		}
+		if claims.Version != user.TokenVersion {
+			respondWithError(w, http.StatusUnauthorized, "Token has been revoked", nil)
+			return
+		}
+		if err := checkAccount(user); err != nil {
+			respondWithError(w, http.StatusForbidden, err.Error(), err)
+			return
+		}
+
+		if sessionID := claims.Session(); sessionID != uuid.Nil {
+			active, err := cfg.authSessions.Get(r.Context(), sessionID)
+			if err != nil {
+				respondWithError(w, http.StatusInternalServerError, "DB error", err)
+				return
+			}
+			if !active {
+				respondWithError(w, http.StatusUnauthorized, "Token has been revoked", nil)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handlerDeactivateAccount switches off the caller's account. Their chirps
+// disappear from reads until they reactivate.
+func (cfg *apiConfig) handlerDeactivateAccount(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	rows, err := cfg.dbQueries.SetUserStatus(r.Context(), database.SetUserStatusParams{
+		Status:     string(account.StatusDeactivated),
+		ID:         userID,
+		FromStatus: string(account.StatusActive),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't deactivate account", err)
+		return
+	}
+	if rows == 0 {
+		respondWithError(w, http.StatusConflict, "Account is not active", nil)
+		return
+	}
+	cfg.authUsers.Forget(userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerReactivateAccount switches a deactivated account back on, or cancels
+// a deletion that is still in its grace period. Such accounts can't get
+// tokens, so it takes the credentials instead.
+func (cfg *apiConfig) handlerReactivateAccount(w http.ResponseWriter, r *http.Request) {
+	var req ReactivateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON", err)
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserByEmail(r.Context(), req.Email)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Incorrect email or password", nil)
+		return
+	}
+	ok, _ := auth.CheckPasswordHash(req.Password, user.HashedPassword)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Incorrect email or password", nil)
+		return
+	}
+
+	err = checkAccount(user)
+	if err == nil {
+		respondWithError(w, http.StatusConflict, "Account is already active", nil)
+		return
+	}
+	switch {
+	case errors.Is(err, account.ErrDeactivated):
+		_, err = cfg.dbQueries.SetUserStatus(r.Context(), database.SetUserStatusParams{
+			Status:     string(account.StatusActive),
+			ID:         user.ID,
+			FromStatus: string(account.StatusDeactivated),
+		})
+	case errors.Is(err, account.ErrDeleted) && user.DeleteAfter.Valid && time.Now().UTC().Before(user.DeleteAfter.Time):
+		_, err = cfg.dbQueries.CancelUserDeletion(r.Context(), user.ID)
+	default:
+		respondWithError(w, http.StatusForbidden, err.Error(), err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reactivate account", err)
+		return
+	}
+	cfg.authUsers.Forget(user.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerSuspendUser suspends a user outside the report queue and records it
+// in the moderation log.
+func (cfg *apiConfig) handlerSuspendUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	var params SuspendUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	reason, err := moderation.ValidateNote(params.Reason)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	var until sql.NullTime
+	if params.Duration != "" {
+		d, err := time.ParseDuration(params.Duration)
+		if err != nil || d <= 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid duration", err)
+			return
+		}
+		until = sql.NullTime{Time: time.Now().UTC().Add(d), Valid: true}
+	}
+
+	cfg.changeSuspension(w, r, userID, moderation.ActionSuspend, reason, func(q *database.Queries) (int64, error) {
+		rows, err := q.SuspendUser(r.Context(), database.SuspendUserParams{
+			ID:               userID,
+			SuspensionReason: reason,
+			SuspendedUntil:   until,
+		})
+		if err != nil || rows == 0 {
+			return rows, err
+		}
+		// A suspended user's sessions don't come back when it lifts
+		return rows, q.RevokeUserRefreshTokens(r.Context(), userID)
+	})
+}
+
+func (cfg *apiConfig) handlerUnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	cfg.changeSuspension(w, r, userID, moderation.ActionUnsuspend, "", func(q *database.Queries) (int64, error) {
+		return q.UnsuspendUser(r.Context(), userID)
+	})
+}
+
+// checkOutranks makes sure a moderator may act on a user, which they may
+// only do to users below their own role. It responds and returns false when
+// they may not.
+func (cfg *apiConfig) checkOutranks(w http.ResponseWriter, r *http.Request, actorID, userID uuid.UUID) bool {
+	if actorID == userID {
+		respondWithError(w, http.StatusForbidden, "You can't moderate yourself", nil)
+		return false
+	}
+
+	actor, err := cfg.dbQueries.GetUserByID(r.Context(), actorID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return false
+	}
+	user, err := cfg.dbQueries.GetUserByID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "User not found", err)
+			return false
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return false
+	}
+
+	if auth.Role(user.Role).AtLeast(auth.Role(actor.Role)) {
+		respondWithError(w, http.StatusForbidden, "You can't moderate a user whose role is at or above yours", nil)
+		return false
+	}
+	return true
+}
+
+// changeSuspension runs update and logs it as action in one transaction.
+// update reports how many users it changed; none means 404.
+func (cfg *apiConfig) changeSuspension(w http.ResponseWriter, r *http.Request, userID uuid.UUID, action moderation.Action, note string, update func(*database.Queries) (int64, error)) {
+	actorID, ok := cfg.moderatorID(w, r)
+	if !ok || !cfg.checkOutranks(w, r, actorID, userID) {
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	rows, err := update(qtx)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update user", err)
+		return
+	}
+	if rows == 0 {
+		respondWithError(w, http.StatusNotFound, "No user to "+string(action), nil)
+		return
+	}
+
+	logged, err := qtx.CreateModerationAction(r.Context(), database.CreateModerationActionParams{
+		UserID:  uuid.NullUUID{UUID: userID, Valid: true},
+		ActorID: uuid.NullUUID{UUID: actorID, Valid: true},
+		Action:  string(action),
+		Note:    note,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record moderation action", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update user", err)
+		return
+	}
+	cfg.authUsers.Forget(userID)
+	respondWithJSON(w, http.StatusOK, moderationActionResponse(logged))
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/entities"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/pagination"
+)
+
+type RelationshipResponse struct {
+	UserID    string `json:"user_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (cfg *apiConfig) handlerBlockUser(w http.ResponseWriter, r *http.Request) {
+	cfg.setRelationship(w, r, true, true)
+}
+
+func (cfg *apiConfig) handlerUnblockUser(w http.ResponseWriter, r *http.Request) {
+	cfg.setRelationship(w, r, true, false)
+}
+
+func (cfg *apiConfig) handlerMuteUser(w http.ResponseWriter, r *http.Request) {
+	cfg.setRelationship(w, r, false, true)
+}
+
+func (cfg *apiConfig) handlerUnmuteUser(w http.ResponseWriter, r *http.Request) {
+	cfg.setRelationship(w, r, false, false)
+}
+
+// setRelationship blocks (or mutes, when block is false) the user in the path
+// for the caller, or lifts it when on is false. Blocking also ends any follow
+// between the two users.
+func (cfg *apiConfig) setRelationship(w http.ResponseWriter, r *http.Request, block, on bool) {
+	targetID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	if targetID == userID {
+		respondWithError(w, http.StatusBadRequest, "You cannot block or mute yourself", nil)
+		return
+	}
+
+	if !on {
+		var rows int64
+		if block {
+			rows, err = cfg.dbQueries.DeleteBlock(r.Context(), database.DeleteBlockParams{BlockerID: userID, BlockedID: targetID})
+		} else {
+			rows, err = cfg.dbQueries.DeleteMute(r.Context(), database.DeleteMuteParams{MuterID: userID, MutedID: targetID})
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't update relationship", err)
+			return
+		}
+		if rows == 0 {
+			respondWithError(w, http.StatusNotFound, "You have not blocked or muted this user", nil)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if _, err := cfg.dbQueries.GetUserByID(r.Context(), targetID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "User not found", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "DB error", err)
+		return
+	}
+
+	if !block {
+		// Muting twice is a no-op rather than an error
+		_, err = cfg.dbQueries.CreateMute(r.Context(), database.CreateMuteParams{MuterID: userID, MutedID: targetID})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't mute user", err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	if _, err := qtx.CreateBlock(r.Context(), database.CreateBlockParams{BlockerID: userID, BlockedID: targetID}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't block user", err)
+		return
+	}
+	err = qtx.DeleteFollowsBetween(r.Context(), database.DeleteFollowsBetweenParams{UserA: userID, UserB: targetID})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't remove follows", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't block user", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerListBlocks(w http.ResponseWriter, r *http.Request) {
+	cfg.listRelationships(w, r, true)
+}
+
+func (cfg *apiConfig) handlerListMutes(w http.ResponseWriter, r *http.Request) {
+	cfg.listRelationships(w, r, false)
+}
+
+// listRelationships writes one page of the users the caller has blocked, or
+// muted when blocks is false, most recent first.
+func (cfg *apiConfig) listRelationships(w http.ResponseWriter, r *http.Request, blocks bool) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit, err := pagination.ParseLimit(query.Get("limit"), defaultChirpsPageSize, maxChirpsPageSize)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := cursorParams(query.Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	// Both lists share one row shape, so list them through the blocks types
+	params := database.ListBlocksPageParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           int32(limit + 1),
+	}
+	var rows []database.ListBlocksPageRow
+	if blocks {
+		rows, err = cfg.dbQueries.ListBlocksPage(r.Context(), params)
+	} else {
+		var muteRows []database.ListMutesPageRow
+		muteRows, err = cfg.dbQueries.ListMutesPage(r.Context(), database.ListMutesPageParams(params))
+		for _, row := range muteRows {
+			rows = append(rows, database.ListBlocksPageRow(row))
+		}
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error while getting relationships from db", err)
+		return
+	}
+
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		setNextPageHeaders(w, r, pagination.EncodeCursor(pagination.Cursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.UserID,
+		}))
+	}
+
+	response := make([]RelationshipResponse, 0, len(rows))
+	for _, row := range rows {
+		response = append(response, RelationshipResponse{
+			UserID:    row.UserID.String(),
+			CreatedAt: row.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// viewerNullID is viewerID in the form the list queries take, so anonymous
+// requests skip block and mute filtering.
+func (cfg *apiConfig) viewerNullID(r *http.Request) uuid.NullUUID {
+	viewerID, ok := cfg.viewerID(r)
+	return uuid.NullUUID{UUID: viewerID, Valid: ok}
+}
+
+// isBlocked reports whether either user has blocked the other.
+func (cfg *apiConfig) isBlocked(ctx context.Context, a, b uuid.UUID) (bool, error) {
+	if a == b {
+		return false, nil
+	}
+	return cfg.dbQueries.IsBlockedEitherWay(ctx, database.IsBlockedEitherWayParams{UserA: a, UserB: b})
+}
+
+// checkMentions responds with 403 and returns false if body mentions a user
+// on the other side of a block from authorID.
+func (cfg *apiConfig) checkMentions(w http.ResponseWriter, r *http.Request, authorID uuid.UUID, body string) bool {
+	username, err := cfg.blockedMention(r.Context(), authorID, body)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error checking blocks", err)
+		return false
+	}
+	if username != "" {
+		respondWithError(w, http.StatusForbidden, "You cannot mention @"+username, nil)
+		return false
+	}
+	return true
+}
+
+// blockedMention returns the first user mentioned in body who is on the
+// other side of a block from authorID, or "" if there is none.
+func (cfg *apiConfig) blockedMention(ctx context.Context, authorID uuid.UUID, body string) (string, error) {
+	mentions := entities.ExtractMentions(body)
+	if len(mentions) == 0 {
+		return "", nil
+	}
+	users, err := cfg.dbQueries.ListUsersByUsernames(ctx, mentions)
+	if err != nil {
+		return "", err
+	}
+	ids := make([]uuid.UUID, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	blocked, err := cfg.dbQueries.ListBlockedUserIDs(ctx, database.ListBlockedUserIDsParams{
+		UserID:  authorID,
+		UserIds: ids,
+	})
+	if err != nil || len(blocked) == 0 {
+		return "", err
+	}
+	for _, u := range users {
+		if u.ID == blocked[0] {
+			return u.Username.String, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+)
+
+type ChirpRevisionResponse struct {
+	ID         string `json:"id"`
+	ChirpID    string `json:"chirp_id"`
+	Body       string `json:"body"`
+	CreatedAt  string `json:"created_at"`
+	ReplacedAt string `json:"replaced_at"`
+}
+
+func chirpRevisionResponse(rev database.ChirpRevision) ChirpRevisionResponse {
+	return ChirpRevisionResponse{
+		ID:         rev.ID.String(),
+		ChirpID:    rev.ChirpID.String(),
+		Body:       rev.Body,
+		CreatedAt:  rev.CreatedAt.Format(time.RFC3339),
+		ReplacedAt: rev.ReplacedAt.Format(time.RFC3339),
+	}
+}
+
+func (cfg *apiConfig) handlerUpdateChirp(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	if !cfg.checkCanPost(w, r, userID) {
+		return
+	}
+
+	var params CreateChirpRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	body, err := cfg.cleanChirpBody(params.Body)
+	if err != nil {
+		respondWithChirpError(w, err)
+		return
+	}
+
+	if !cfg.checkMentions(w, r, userID, body) {
+		return
+	}
+
+	// Lock the chirp so concurrent edits can't lose a revision
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	chirp, err := qtx.GetChirpByIDForUpdate(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+		return
+	}
+
+	if chirp.UserID != userID {
+		respondWithError(w, http.StatusForbidden, "Forbidden: you cannot edit another user's chirp", nil)
+		return
+	}
+
+	if chirp.RepostKind.String == repostKindRechirp {
+		respondWithError(w, http.StatusBadRequest, "Rechirps have no body to edit", nil)
+		return
+	}
+
+	if time.Now().UTC().Sub(chirp.CreatedAt) > cfg.chirpEditWindow {
+		respondWithError(w, http.StatusForbidden, "Edit window has expired", nil)
+		return
+	}
+
+	// Keep the body being replaced, stamped with when it was written
+	_, err = qtx.CreateChirpRevision(r.Context(), database.CreateChirpRevisionParams{
+		ChirpID:   chirp.ID,
+		Body:      chirp.Body,
+		CreatedAt: chirp.UpdatedAt,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save chirp revision", err)
+		return
+	}
+
+	updated, err := qtx.UpdateChirpBody(r.Context(), database.UpdateChirpBodyParams{
+		ID:   chirp.ID,
+		Body: body,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+
+	if err := saveChirpHashtags(r.Context(), qtx, updated.ID, updated.Body); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp hashtags", err)
+		return
+	}
+
+	if err := cfg.saveProfanityFlag(r.Context(), qtx, updated); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp review flag", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+
+	resp, err := cfg.buildChirpResponses(r, []database.Chirp{updated})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error building chirp", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp[0])
+}
+
+func (cfg *apiConfig) handlerGetChirpRevisions(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return
+	}
+
+	chirp, err := cfg.dbQueries.GetChirpByID(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+		return
+	}
+
+	// Earlier bodies are as private as the chirp itself
+	visible, err := cfg.chirpVisible(r.Context(), chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+		return
+	}
+	if !visible {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+		return
+	}
+	if viewerID, ok := cfg.viewerID(r); ok {
+		blocked, err := cfg.isBlocked(r.Context(), viewerID, chirp.UserID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error checking blocks", err)
+			return
+		}
+		if blocked {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+	}
+
+	revisions, err := cfg.dbQueries.ListChirpRevisions(r.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error fetching chirp revisions", err)
+		return
+	}
+
+	response := make([]ChirpRevisionResponse, 0, len(revisions))
+	for _, rev := range revisions {
+		response = append(response, chirpRevisionResponse(rev))
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/pagination"
+)
+
+const (
+	defaultThreadDepth = 5
+	maxThreadDepth     = 20
+	// maxThreadDescendants caps how many nested replies one page can pull in.
+	maxThreadDescendants = 500
+)
+
+type ThreadChirpResponse struct {
+	CreateChirpResponse
+	ReplyCount int64                 `json:"reply_count"`
+	Replies    []ThreadChirpResponse `json:"replies,omitempty"`
+}
+
+type ThreadResponse struct {
+	Ancestors  []ThreadChirpResponse `json:"ancestors"`
+	Chirp      ThreadChirpResponse   `json:"chirp"`
+	Replies    []ThreadChirpResponse `json:"replies"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+// handlerGetChirpThread returns a chirp with its chain of ancestors and a page
+// of its direct replies, each carrying its own nested replies down to max_depth.
+func (cfg *apiConfig) handlerGetChirpThread(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit, err := pagination.ParseLimit(query.Get("limit"), defaultChirpsPageSize, maxChirpsPageSize)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	maxDepth := defaultThreadDepth
+	if depthStr := query.Get("max_depth"); depthStr != "" {
+		maxDepth, err = strconv.Atoi(depthStr)
+		if err != nil || maxDepth < 1 {
+			respondWithError(w, http.StatusBadRequest, "Invalid max_depth", err)
+			return
+		}
+		if maxDepth > maxThreadDepth {
+			maxDepth = maxThreadDepth
+		}
+	}
+
+	cursorCreatedAt, cursorID, err := cursorParams(query.Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	chirp, err := cfg.dbQueries.GetChirpByID(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+		return
+	}
+	visible, err := cfg.chirpVisible(r.Context(), chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+		return
+	}
+	if !visible {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+		return
+	}
+
+	if viewerID, ok := cfg.viewerID(r); ok {
+		blocked, err := cfg.isBlocked(r.Context(), viewerID, chirp.UserID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error checking blocks", err)
+			return
+		}
+		if blocked {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+	}
+
+	viewerID := cfg.viewerNullID(r)
+	replyCount, err := cfg.dbQueries.CountChirpReplies(r.Context(), database.CountChirpRepliesParams{
+		ViewerID: viewerID,
+		ID:       chirp.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error counting replies", err)
+		return
+	}
+
+	ancestors, err := cfg.dbQueries.ListChirpAncestors(r.Context(), database.ListChirpAncestorsParams{
+		ID:       chirp.ID,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error fetching ancestors", err)
+		return
+	}
+
+	// Fetch one extra direct reply so we know whether there is a next page
+	replies, err := cfg.dbQueries.ListChirpRepliesPage(r.Context(), database.ListChirpRepliesPageParams{
+		ViewerID:        viewerID,
+		ParentID:        chirp.ID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error fetching replies", err)
+		return
+	}
+
+	var nextCursor string
+	if len(replies) > limit {
+		replies = replies[:limit]
+		last := replies[len(replies)-1].Chirp
+		nextCursor = pagination.EncodeCursor(pagination.Cursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		})
+		setNextPageHeaders(w, r, nextCursor)
+	}
+
+	// Pull in the nested replies under this page of direct replies
+	rootIDs := make([]uuid.UUID, 0, len(replies))
+	for _, reply := range replies {
+		rootIDs = append(rootIDs, reply.Chirp.ID)
+	}
+	var descendants []database.ListChirpDescendantsRow
+	if len(rootIDs) > 0 && maxDepth > 1 {
+		descendants, err = cfg.dbQueries.ListChirpDescendants(r.Context(), database.ListChirpDescendantsParams{
+			RootIds:  rootIDs,
+			ViewerID: viewerID,
+			MaxDepth: int32(maxDepth - 1),
+			Limit:    maxThreadDescendants,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error fetching replies", err)
+			return
+		}
+	}
+
+	// Build the whole thread in one go, so its chirps look like chirps in any
+	// other list. The order is the chirp, its ancestors, the direct replies,
+	// then the nested ones.
+	chirps := []database.Chirp{chirp}
+	replyCounts := []int64{replyCount}
+	for _, a := range ancestors {
+		chirps = append(chirps, a.Chirp)
+		replyCounts = append(replyCounts, a.ReplyCount)
+	}
+	for _, reply := range replies {
+		chirps = append(chirps, reply.Chirp)
+		replyCounts = append(replyCounts, reply.ReplyCount)
+	}
+	for _, d := range descendants {
+		chirps = append(chirps, d.Chirp)
+		replyCounts = append(replyCounts, d.ReplyCount)
+	}
+	built, err := cfg.buildChirpResponses(r, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error building chirps", err)
+		return
+	}
+	if err := cfg.applyUserFilters(r, built); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error applying filters", err)
+		return
+	}
+	nodes := make([]ThreadChirpResponse, len(built))
+	for i := range built {
+		nodes[i] = ThreadChirpResponse{
+			CreateChirpResponse: built[i],
+			ReplyCount:          replyCounts[i],
+		}
+	}
+
+	firstReply := 1 + len(ancestors)
+	resp := ThreadResponse{
+		Ancestors:  nodes[1:firstReply],
+		Chirp:      nodes[0],
+		Replies:    make([]ThreadChirpResponse, 0, len(replies)),
+		NextCursor: nextCursor,
+	}
+
+	// Group descendants by parent, then assemble the tree from the direct replies down
+	children := make(map[uuid.UUID][]int)
+	for i := firstReply + len(replies); i < len(chirps); i++ {
+		parentID := chirps[i].ParentID.UUID
+		children[parentID] = append(children[parentID], i)
+	}
+	var build func(i int) ThreadChirpResponse
+	build = func(i int) ThreadChirpResponse {
+		node := nodes[i]
+		for _, child := range children[chirps[i].ID] {
+			node.Replies = append(node.Replies, build(child))
+		}
+		return node
+	}
+	for i := range replies {
+		resp.Replies = append(resp.Replies, build(firstReply+i))
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
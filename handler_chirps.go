@@ -1,21 +1,26 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"sort"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/chirptext"
 	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/pagination"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/profanity"
 )
 
 type CreateChirpRequest struct {
-	Body string `json:"body"`
+	Body      string `json:"body"`
+	InReplyTo string `json:"in_reply_to"`
 }
 
 type CreateChirpResponse struct {
@@ -24,13 +29,151 @@ type CreateChirpResponse struct {
 	UpdatedAt string `json:"updated_at"`
 	Body      string `json:"body"`
 	UserID    string `json:"user_id"`
+	// Author is filled in by buildChirpResponses.
+	Author    *AuthorResponse `json:"author,omitempty"`
+	InReplyTo string          `json:"in_reply_to,omitempty"`
+	LikeCount int32           `json:"like_count"`
+	// LikedByMe is only set when the request carries a bearer token.
+	LikedByMe *bool `json:"liked_by_me,omitempty"`
+	// RepostKind is "rechirp" or "quote" when this chirp points at another one.
+	RepostKind      string               `json:"repost_kind,omitempty"`
+	Original        *CreateChirpResponse `json:"original,omitempty"`
+	OriginalDeleted bool                 `json:"original_deleted,omitempty"`
+	// Filtered marks a chirp the viewer muted but chose to collapse, not hide.
+	Filtered bool `json:"filtered,omitempty"`
+}
+
+// chirpResponse converts a database chirp into its API representation.
+func chirpResponse(c database.Chirp) CreateChirpResponse {
+	resp := CreateChirpResponse{
+		ID:        c.ID.String(),
+		CreatedAt: c.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: c.UpdatedAt.Format(time.RFC3339),
+		Body:      c.Body,
+		UserID:    c.UserID.String(),
+		LikeCount: c.LikeCount,
+	}
+	if c.ParentID.Valid {
+		resp.InReplyTo = c.ParentID.UUID.String()
+	}
+	if c.RepostKind.Valid {
+		resp.RepostKind = c.RepostKind.String
+		// original_id is cleared by ON DELETE SET NULL when the original goes away
+		resp.OriginalDeleted = !c.OriginalID.Valid
+	}
+	return resp
+}
+
+// buildChirpResponses converts chirps into API responses along with the data
+// that lives in other rows or depends on the viewer: the authors, the embedded
+// original of rechirps and quotes, and liked_by_me.
+func (cfg *apiConfig) buildChirpResponses(r *http.Request, chirps []database.Chirp) ([]CreateChirpResponse, error) {
+	response := make([]CreateChirpResponse, 0, len(chirps))
+	originalIDs := []uuid.UUID{}
+	for _, c := range chirps {
+		response = append(response, chirpResponse(c))
+		if c.OriginalID.Valid {
+			originalIDs = append(originalIDs, c.OriginalID.UUID)
+		}
+	}
+
+	if err := cfg.setLikedByMe(r, response); err != nil {
+		return nil, err
+	}
+
+	var originals []database.Chirp
+	if len(originalIDs) > 0 {
+		var err error
+		originals, err = cfg.dbQueries.ListChirpsByIDs(r.Context(), originalIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	byID := make(map[uuid.UUID]database.Chirp, len(originals))
+	for _, o := range originals {
+		byID[o.ID] = o
+	}
+
+	// Don't embed originals written by someone on the other side of a block
+	if viewerID, ok := cfg.viewerID(r); ok && len(originals) > 0 {
+		originalAuthors := make([]uuid.UUID, 0, len(originals))
+		for _, o := range originals {
+			originalAuthors = append(originalAuthors, o.UserID)
+		}
+		blocked, err := cfg.dbQueries.ListBlockedUserIDs(r.Context(), database.ListBlockedUserIDsParams{
+			UserID:  viewerID,
+			UserIds: originalAuthors,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range originals {
+			for _, b := range blocked {
+				if o.UserID == b {
+					delete(byID, o.ID)
+				}
+			}
+		}
+	}
+
+	// Load every author, including those of the originals, in one query
+	authorIDs := make([]uuid.UUID, 0, len(chirps)+len(originals))
+	for _, c := range chirps {
+		authorIDs = append(authorIDs, c.UserID)
+	}
+	for _, o := range originals {
+		authorIDs = append(authorIDs, o.UserID)
+	}
+	authors := map[uuid.UUID]AuthorResponse{}
+	inactive := map[uuid.UUID]bool{}
+	if len(authorIDs) > 0 {
+		users, err := cfg.dbQueries.ListUsersByIDs(r.Context(), authorIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			authors[u.ID] = authorResponse(u)
+			inactive[u.ID] = checkAccount(u) != nil
+		}
+	}
+
+	for i, c := range chirps {
+		if a, ok := authors[c.UserID]; ok {
+			response[i].Author = &a
+		}
+		if !c.OriginalID.Valid {
+			continue
+		}
+		// Originals that couldn't be read on their own are left out of the embed
+		if o, ok := byID[c.OriginalID.UUID]; ok && !o.HiddenAt.Valid && !inactive[o.UserID] {
+			original := chirpResponse(o)
+			if a, ok := authors[o.UserID]; ok {
+				original.Author = &a
+			}
+			response[i].Original = &original
+		}
+	}
+	return response, nil
+}
+
+// chirpVisible reports whether a chirp can be read: a moderator hasn't hidden
+// it and its author's account is active.
+func (cfg *apiConfig) chirpVisible(ctx context.Context, chirp database.Chirp) (bool, error) {
+	if chirp.HiddenAt.Valid {
+		return false, nil
+	}
+	author, err := cfg.dbQueries.GetUserByID(ctx, chirp.UserID)
+	if err != nil {
+		return false, err
+	}
+	return checkAccount(author) == nil, nil
 }
 
 func (cfg *apiConfig) handlerGetChirpByID(w http.ResponseWriter, r *http.Request) {
 	// Expected URL: /api/chirps/{chirpID}
 	pathParts := strings.Split(r.URL.Path, "/")
 	if len(pathParts) < 4 {
-		http.NotFound(w, r)
+		respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
 		return
 	}
 	chirpIDStr := pathParts[3]
@@ -44,69 +187,120 @@ func (cfg *apiConfig) handlerGetChirpByID(w http.ResponseWriter, r *http.Request
 	chirp, err := cfg.dbQueries.GetChirpByID(r.Context(), chirpUUID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			http.NotFound(w, r)
+			respondWithError(w, http.StatusNotFound, "Chirp not found", err)
 			return
 		}
 		respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
 		return
 	}
+	visible, err := cfg.chirpVisible(r.Context(), chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+		return
+	}
+	if !visible {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+		return
+	}
 
-	resp := CreateChirpResponse{
-		ID:        chirp.ID.String(),
-		CreatedAt: chirp.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: chirp.UpdatedAt.Format(time.RFC3339),
-		Body:      chirp.Body,
-		UserID:    chirp.UserID.String(),
+	// A block hides chirps in both directions, as if they didn't exist
+	if viewerID, ok := cfg.viewerID(r); ok {
+		blocked, err := cfg.isBlocked(r.Context(), viewerID, chirp.UserID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error checking blocks", err)
+			return
+		}
+		if blocked {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
 	}
-	respondWithJSON(w, http.StatusOK, resp)
+
+	resp, err := cfg.buildChirpResponses(r, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error building chirp", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp[0])
 }
 
+const (
+	defaultChirpsPageSize = 20
+	maxChirpsPageSize     = 100
+)
+
 func (cfg *apiConfig) handlerGetChirps(w http.ResponseWriter, r *http.Request) {
-	// Check if author_id query parameter is provided
-	authorIDStr := r.URL.Query().Get("author_id")
+	query := r.URL.Query()
 
-	var chirps []database.Chirp
-	var err error
+	limit, err := pagination.ParseLimit(query.Get("limit"), defaultChirpsPageSize, maxChirpsPageSize)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
 
-	if authorIDStr != "" {
-		// Filter by specific author
+	// Check if author_id query parameter is provided
+	var authorID uuid.NullUUID
+	if authorIDStr := query.Get("author_id"); authorIDStr != "" {
 		authorUUID, err := uuid.Parse(authorIDStr)
 		if err != nil {
 			respondWithError(w, http.StatusBadRequest, "Invalid author ID format", err)
 			return
 		}
-		chirps, err = cfg.dbQueries.ListChirpsByUser(r.Context(), authorUUID)
-	} else {
-		// Get all chirps
-		chirps, err = cfg.dbQueries.ListChirps(r.Context())
+		authorID = uuid.NullUUID{UUID: authorUUID, Valid: true}
 	}
 
+	// Resume after the cursor from the previous page, if any
+	cursorCreatedAt, cursorID, err := cursorParams(query.Get("cursor"))
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Error while getting chirps from db", err)
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
 		return
 	}
 
-	sortParam := r.URL.Query().Get("sort")
+	viewerID := cfg.viewerNullID(r)
 
-	sort.Slice(chirps, func(i, j int) bool {
-		if sortParam == "desc" {
-			return chirps[i].CreatedAt.After(chirps[j].CreatedAt)
-		}
+	// Fetch one extra row so we know whether there is a next page
+	var chirps []database.Chirp
+	if query.Get("sort") == "desc" {
+		chirps, err = cfg.dbQueries.ListChirpsPageDesc(r.Context(), database.ListChirpsPageDescParams{
+			AuthorID:        authorID,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorID,
+			ViewerID:        viewerID,
+			Limit:           int32(limit + 1),
+		})
+	} else {
 		// default: asc
-		return chirps[i].CreatedAt.Before(chirps[j].CreatedAt)
-	})
+		chirps, err = cfg.dbQueries.ListChirpsPageAsc(r.Context(), database.ListChirpsPageAscParams{
+			AuthorID:        authorID,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorID,
+			ViewerID:        viewerID,
+			Limit:           int32(limit + 1),
+		})
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error while getting chirps from db", err)
+		return
+	}
 
-	// convert []Chirp -> []CreateChirpResponse
-	response := make([]CreateChirpResponse, 0, len(chirps))
+	if len(chirps) > limit {
+		chirps = chirps[:limit]
+		last := chirps[len(chirps)-1]
+		setNextPageHeaders(w, r, pagination.EncodeCursor(pagination.Cursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		}))
+	}
 
-	for _, c := range chirps {
-		response = append(response, CreateChirpResponse{
-			ID:        c.ID.String(),
-			CreatedAt: c.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: c.UpdatedAt.Format(time.RFC3339),
-			Body:      c.Body,
-			UserID:    c.UserID.String(),
-		})
+	// convert []Chirp -> []CreateChirpResponse
+	response, err := cfg.buildChirpResponses(r, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error building chirps", err)
+		return
+	}
+	if err := cfg.applyUserFilters(r, response); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error applying filters", err)
+		return
 	}
 	respondWithJSON(w, http.StatusOK, response)
 }
@@ -127,40 +321,161 @@ func (cfg *apiConfig) handlerChirps(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID, err := auth.ValidateJWT(tokenString, cfg.jwtSecret)
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
 		return
 	}
 
-	const maxChirpLength = 140
-	if len(params.Body) > maxChirpLength {
-		respondWithError(w, http.StatusBadRequest, "Chirp is too long", nil)
+	if userID.String() == "" {
+		respondWithError(w, http.StatusBadRequest, "user id is missing", nil)
+		return
+	}
+	if !cfg.checkCanPost(w, r, userID) {
 		return
 	}
 
-	if userID.String() == "" {
-		respondWithError(w, http.StatusBadRequest, "user id is missing", nil)
+	params.Body, err = cfg.cleanChirpBody(params.Body)
+	if err != nil {
+		respondWithChirpError(w, err)
 		return
 	}
 
-	// Now add the profanity checker
-	params.Body = profanityCleaner(params.Body)
-	chirp, err := cfg.dbQueries.CreateChirp(r.Context(), database.CreateChirpParams{
-		Body:   params.Body,
-		UserID: userID,
+	// Replies must point at an existing chirp
+	var parentID uuid.NullUUID
+	if params.InReplyTo != "" {
+		parentUUID, err := uuid.Parse(params.InReplyTo)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid in_reply_to chirp ID", err)
+			return
+		}
+		parent, err := cfg.dbQueries.GetChirpByID(r.Context(), parentUUID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusNotFound, "Chirp being replied to not found", err)
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+			return
+		}
+		visible, err := cfg.chirpVisible(r.Context(), parent)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+			return
+		}
+		if !visible {
+			respondWithError(w, http.StatusNotFound, "Chirp being replied to not found", nil)
+			return
+		}
+		blocked, err := cfg.isBlocked(r.Context(), userID, parent.UserID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error checking blocks", err)
+			return
+		}
+		if blocked {
+			respondWithError(w, http.StatusForbidden, "You cannot reply to this user", nil)
+			return
+		}
+		parentID = uuid.NullUUID{UUID: parentUUID, Valid: true}
+	}
+
+	if !cfg.checkMentions(w, r, userID, params.Body) {
+		return
+	}
+
+	chirp, err := cfg.createChirp(r.Context(), database.CreateChirpParams{
+		Body:     params.Body,
+		UserID:   userID,
+		ParentID: parentID,
 	})
 
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
 		return
 	}
-	respondWithJSON(w, http.StatusCreated, CreateChirpResponse{
-		ID:        chirp.ID.String(),
-		CreatedAt: chirp.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: chirp.UpdatedAt.Format(time.RFC3339),
-		Body:      chirp.Body,
-		UserID:    chirp.UserID.String(),
+	respondWithJSON(w, http.StatusCreated, chirpResponse(chirp))
+}
+
+// createChirp inserts a chirp, indexes its hashtags, flags it for review if
+// needed and notifies the users it replies to or mentions, all in one
+// transaction.
+func (cfg *apiConfig) createChirp(ctx context.Context, params database.CreateChirpParams) (database.Chirp, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	chirp, err := qtx.CreateChirp(ctx, params)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+
+	if err := saveChirpHashtags(ctx, qtx, chirp.ID, chirp.Body); err != nil {
+		return database.Chirp{}, err
+	}
+
+	if err := cfg.saveProfanityFlag(ctx, qtx, chirp); err != nil {
+		return database.Chirp{}, err
+	}
+
+	if err := notifyChirpCreated(ctx, qtx, chirp); err != nil {
+		return database.Chirp{}, err
+	}
+
+	return chirp, tx.Commit()
+}
+
+// cleanChirpBody runs the validation pipeline and profanity filter shared by
+// every endpoint that writes a chirp body. Rule violations come back as a
+// *chirptext.ValidationError.
+func (cfg *apiConfig) cleanChirpBody(body string) (string, error) {
+	body, err := cfg.chirpValidator.Validate(body)
+	if err != nil {
+		return "", err
+	}
+
+	// Now add the profanity checker
+	matches := cfg.profanity.Find(body)
+	if len(matches) == 0 {
+		return body, nil
+	}
+	switch cfg.profanityMode {
+	case profanity.ModeReject:
+		violations := make([]chirptext.Violation, 0, len(matches))
+		for _, m := range matches {
+			pos := chirptext.Length(body[:m.Start])
+			violations = append(violations, chirptext.Violation{
+				Code:     chirptext.CodeProfanity,
+				Message:  "Chirp contains a blocked word",
+				Position: &pos,
+			})
+		}
+		return "", &chirptext.ValidationError{Violations: violations}
+	case profanity.ModeFlag:
+		// Kept as written; saveProfanityFlag queues it for review once saved
+		return body, nil
+	}
+	return profanity.Mask(body, matches), nil
+}
+
+type ChirpViolationsResponse struct {
+	Error      string                `json:"error"`
+	Violations []chirptext.Violation `json:"violations"`
+}
+
+// respondWithChirpError reports an error from cleanChirpBody, listing every
+// rule the body broke.
+func respondWithChirpError(w http.ResponseWriter, err error) {
+	var verr *chirptext.ValidationError
+	if !errors.As(err, &verr) {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't validate chirp", err)
+		return
+	}
+	respondWithJSON(w, http.StatusBadRequest, ChirpViolationsResponse{
+		Error:      verr.Violations[0].Message,
+		Violations: verr.Violations,
 	})
 }
 
@@ -186,7 +501,7 @@ func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	userID, err := auth.ValidateJWT(tokenStr, cfg.jwtSecret)
+	userID, err := auth.ValidateJWT(tokenStr, cfg.jwtKeys)
 	if err != nil {
 		respondWithError(w, 401, "Invalid token", err)
 		return
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/mail"
+)
+
+const emailIndex = "users_email_key"
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// issueEmailVerification stores a token proving ownership of email for the
+// user and returns the message carrying it. Any earlier token stops working,
+// so only the most recently requested address can be confirmed. The caller
+// sends the message once its transaction has committed.
+func (cfg *apiConfig) issueEmailVerification(ctx context.Context, q *database.Queries, userID uuid.UUID, email string) (mail.Message, error) {
+	token, hash, err := auth.MakeOneTimeToken()
+	if err != nil {
+		return mail.Message{}, err
+	}
+	if err := q.InvalidateEmailVerificationTokens(ctx, userID); err != nil {
+		return mail.Message{}, err
+	}
+	err = q.CreateEmailVerificationToken(ctx, database.CreateEmailVerificationTokenParams{
+		TokenHash: hash,
+		UserID:    userID,
+		Email:     email,
+		ExpiresAt: time.Now().UTC().Add(cfg.emailVerifyTTL),
+	})
+	if err != nil {
+		return mail.Message{}, err
+	}
+	return mail.Message{
+		To:      email,
+		Subject: "Confirm your email address for Chirpy",
+		Body: fmt.Sprintf("Use this token to confirm %s as the email address for your Chirpy account:\n\n%s\n\n"+
+			"It expires in %s. If you didn't ask for this, you can ignore this email.\n",
+			email, token, cfg.emailVerifyTTL),
+	}, nil
+}
+
+// handlerVerifyEmail confirms an address using a token from
+// issueEmailVerification. For a pending change of address this is the point
+// where the account's email actually changes.
+func (cfg *apiConfig) handlerVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON", err)
+		return
+	}
+	if req.Token == "" {
+		respondWithError(w, http.StatusBadRequest, "Token is required", nil)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	verified, err := qtx.ConsumeEmailVerificationToken(r.Context(), database.ConsumeEmailVerificationTokenParams{
+		TokenHash: auth.HashOneTimeToken(req.Token),
+		ExpiresAt: time.Now().UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusBadRequest, "Invalid or expired verification token", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't check verification token", err)
+		return
+	}
+
+	user, err := qtx.ConfirmUserEmail(r.Context(), database.ConfirmUserEmailParams{
+		ID:    verified.UserID,
+		Email: verified.Email,
+	})
+	if err != nil {
+		// Someone else signed up with the address while the change was pending
+		if isUniqueViolation(err, emailIndex) {
+			respondWithError(w, http.StatusConflict, "Email is already in use", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't confirm email", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't confirm email", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, userResponse(user))
+}
+
+// handlerResendVerification sends a fresh verification token for the
+// caller's current address.
+func (cfg *apiConfig) handlerResendVerification(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+	if user.EmailVerifiedAt.Valid {
+		respondWithError(w, http.StatusConflict, "Email is already verified", nil)
+		return
+	}
+
+	msg, err := cfg.issueEmailVerification(r.Context(), cfg.dbQueries, user.ID, user.Email)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create verification token", err)
+		return
+	}
+	cfg.sendMail(msg)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// checkCanPost reports whether the user may post or edit chirps, writing a
+// 403 if verified email is required and they haven't got one.
+func (cfg *apiConfig) checkCanPost(w http.ResponseWriter, r *http.Request, userID uuid.UUID) bool {
+	if !cfg.requireVerified {
+		return true
+	}
+	user, err := cfg.dbQueries.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return false
+	}
+	if !user.EmailVerifiedAt.Valid {
+		respondWithError(w, http.StatusForbidden, "Verify your email address before posting", nil)
+		return false
+	}
+	return true
+}
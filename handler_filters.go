@@ -0,0 +1,176 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/contentfilter"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+)
+
+type UserFiltersRequest struct {
+	MutedWords    []string `json:"muted_words"`
+	MutedHashtags []string `json:"muted_hashtags"`
+	MutedUserIDs  []string `json:"muted_user_ids"`
+	// Action is "hide" (the default) or "collapse".
+	Action string `json:"action"`
+}
+
+type UserFiltersResponse struct {
+	MutedWords    []string             `json:"muted_words"`
+	MutedHashtags []string             `json:"muted_hashtags"`
+	MutedUserIDs  []string             `json:"muted_user_ids"`
+	Action        contentfilter.Action `json:"action"`
+}
+
+func userFiltersResponse(f database.UserFilter) UserFiltersResponse {
+	resp := UserFiltersResponse{
+		MutedWords:    f.MutedWords,
+		MutedHashtags: f.MutedHashtags,
+		MutedUserIDs:  make([]string, 0, len(f.MutedUserIds)),
+		Action:        contentfilter.Action(f.Action),
+	}
+	for _, id := range f.MutedUserIds {
+		resp.MutedUserIDs = append(resp.MutedUserIDs, id.String())
+	}
+	return resp
+}
+
+// getUserFilters returns the user's filters, or empty ones if they never
+// saved any.
+func (cfg *apiConfig) getUserFilters(r *http.Request, userID uuid.UUID) (database.UserFilter, error) {
+	filters, err := cfg.dbQueries.GetUserFilters(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.UserFilter{
+			UserID:        userID,
+			MutedWords:    []string{},
+			MutedHashtags: []string{},
+			MutedUserIds:  []uuid.UUID{},
+			Action:        string(contentfilter.ActionHide),
+		}, nil
+	}
+	return filters, err
+}
+
+func (cfg *apiConfig) handlerGetUserFilters(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	filters, err := cfg.getUserFilters(r, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error fetching filters", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, userFiltersResponse(filters))
+}
+
+// handlerPutUserFilters replaces the caller's filters with the ones in the body.
+func (cfg *apiConfig) handlerPutUserFilters(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	var params UserFiltersRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	words, err := contentfilter.NormalizeWords(params.MutedWords)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	hashtags, err := contentfilter.NormalizeHashtags(params.MutedHashtags)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	users, err := contentfilter.NormalizeUsers(params.MutedUserIDs)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	action, err := contentfilter.ParseAction(params.Action)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Action must be hide or collapse", err)
+		return
+	}
+
+	filters, err := cfg.dbQueries.UpsertUserFilters(r.Context(), database.UpsertUserFiltersParams{
+		UserID:        userID,
+		MutedWords:    words,
+		MutedHashtags: hashtags,
+		MutedUserIds:  users,
+		Action:        string(action),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save filters", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, userFiltersResponse(filters))
+}
+
+// collapsedChirps returns which of ids the viewer's filters collapse. The
+// chirps they hide are already left out by the list queries, through the same
+// chirp_filter_action. Anonymous requests collapse nothing.
+func (cfg *apiConfig) collapsedChirps(r *http.Request, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	viewerID, ok := cfg.viewerID(r)
+	if !ok || len(ids) == 0 {
+		return nil, nil
+	}
+	collapsed, err := cfg.dbQueries.ListCollapsedChirpIDs(r.Context(), database.ListCollapsedChirpIDsParams{
+		ChirpIds: ids,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[uuid.UUID]bool, len(collapsed))
+	for _, id := range collapsed {
+		set[id] = true
+	}
+	return set, nil
+}
+
+// applyUserFilters marks the chirps the viewer's filters collapse.
+func (cfg *apiConfig) applyUserFilters(r *http.Request, chirps []CreateChirpResponse) error {
+	ids := make([]uuid.UUID, 0, len(chirps))
+	for _, c := range chirps {
+		id, err := uuid.Parse(c.ID)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	collapsed, err := cfg.collapsedChirps(r, ids)
+	if err != nil {
+		return err
+	}
+	for i, id := range ids {
+		chirps[i].Filtered = collapsed[id]
+	}
+	return nil
+}
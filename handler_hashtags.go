@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/entities"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/pagination"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/trending"
+)
+
+const maxTrendingHashtags = 50
+
+type TrendingResponse struct {
+	Hashtags   []trending.Tag `json:"hashtags"`
+	ComputedAt string         `json:"computed_at,omitempty"`
+}
+
+// saveChirpHashtags replaces the hashtags indexed for a chirp with the ones in
+// its body. Pass a transaction-bound q so the index changes with the chirp.
+func saveChirpHashtags(ctx context.Context, q *database.Queries, chirpID uuid.UUID, body string) error {
+	if err := q.DeleteChirpHashtags(ctx, chirpID); err != nil {
+		return err
+	}
+	for _, tag := range entities.ExtractHashtags(body) {
+		hashtag, err := q.UpsertHashtag(ctx, tag)
+		if err != nil {
+			return err
+		}
+		err = q.CreateChirpHashtag(ctx, database.CreateChirpHashtagParams{
+			ChirpID:   chirpID,
+			HashtagID: hashtag.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handlerGetHashtagChirps(w http.ResponseWriter, r *http.Request) {
+	tag := strings.ToLower(strings.TrimPrefix(r.PathValue("tag"), "#"))
+	if tag == "" {
+		respondWithError(w, http.StatusBadRequest, "Missing hashtag", nil)
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit, err := pagination.ParseLimit(query.Get("limit"), defaultChirpsPageSize, maxChirpsPageSize)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := cursorParams(query.Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	// Fetch one extra row so we know whether there is a next page
+	chirps, err := cfg.dbQueries.ListChirpsByHashtagPage(r.Context(), database.ListChirpsByHashtagPageParams{
+		Tag:             tag,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		ViewerID:        cfg.viewerNullID(r),
+		Limit:           int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error while getting chirps from db", err)
+		return
+	}
+
+	if len(chirps) > limit {
+		chirps = chirps[:limit]
+		last := chirps[len(chirps)-1]
+		setNextPageHeaders(w, r, pagination.EncodeCursor(pagination.Cursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		}))
+	}
+
+	response, err := cfg.buildChirpResponses(r, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error building chirps", err)
+		return
+	}
+	if err := cfg.applyUserFilters(r, response); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error applying filters", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// handlerTrending serves the ranking computed in the background by cfg.trending.
+func (cfg *apiConfig) handlerTrending(w http.ResponseWriter, r *http.Request) {
+	tags, computedAt := cfg.trending.Get()
+
+	resp := TrendingResponse{Hashtags: tags}
+	if resp.Hashtags == nil {
+		resp.Hashtags = []trending.Tag{}
+	}
+	if !computedAt.IsZero() {
+		resp.ComputedAt = computedAt.Format(time.RFC3339)
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// trendingLoader ranks hashtags used within window, each use decaying by half
+// every halfLife.
+func trendingLoader(q *database.Queries, window, halfLife time.Duration) trending.Loader {
+	return func(ctx context.Context) ([]trending.Tag, error) {
+		rows, err := q.ListTrendingHashtags(ctx, database.ListTrendingHashtagsParams{
+			HalfLifeSeconds: halfLife.Seconds(),
+			WindowSeconds:   window.Seconds(),
+			Limit:           maxTrendingHashtags,
+		})
+		if err != nil {
+			return nil, err
+		}
+		tags := make([]trending.Tag, 0, len(rows))
+		for _, row := range rows {
+			tags = append(tags, trending.Tag{
+				Tag:   row.Tag,
+				Uses:  row.Uses,
+				Score: row.Score,
+			})
+		}
+		return tags, nil
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/pagination"
+)
+
+type LikedChirpResponse struct {
+	CreateChirpResponse
+	LikedAt string `json:"liked_at"`
+}
+
+func (cfg *apiConfig) handlerLikeChirp(w http.ResponseWriter, r *http.Request) {
+	cfg.setChirpLike(w, r, true)
+}
+
+func (cfg *apiConfig) handlerUnlikeChirp(w http.ResponseWriter, r *http.Request) {
+	cfg.setChirpLike(w, r, false)
+}
+
+// setChirpLike likes or unlikes the chirp in the path for the caller and
+// responds with the chirp's updated like state.
+func (cfg *apiConfig) setChirpLike(w http.ResponseWriter, r *http.Request, like bool) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	// like_count follows chirp_likes through a trigger, and a duplicate like
+	// or unlike affects zero rows, so racing requests can't double count.
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	chirp, err := qtx.GetChirpByID(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+		return
+	}
+	visible, err := cfg.chirpVisible(r.Context(), chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+		return
+	}
+	if like && !visible {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+		return
+	}
+
+	// A block hides chirps in both directions, as if they didn't exist
+	blocked, err := cfg.isBlocked(r.Context(), userID, chirp.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error checking blocks", err)
+		return
+	}
+	if blocked {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+		return
+	}
+
+	var rows int64
+	if like {
+		rows, err = qtx.CreateChirpLike(r.Context(), database.CreateChirpLikeParams{
+			UserID:  userID,
+			ChirpID: chirpID,
+		})
+	} else {
+		rows, err = qtx.DeleteChirpLike(r.Context(), database.DeleteChirpLikeParams{
+			UserID:  userID,
+			ChirpID: chirpID,
+		})
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update like", err)
+		return
+	}
+
+	if rows > 0 {
+		// Re-read the chirp for the count the trigger just updated
+		chirp, err = qtx.GetChirpByID(r.Context(), chirpID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+			return
+		}
+	}
+
+	if rows > 0 && like {
+		err = notify(r.Context(), qtx, chirp.UserID, userID, notificationLike, uuid.NullUUID{UUID: chirpID, Valid: true})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't record notification", err)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update like", err)
+		return
+	}
+
+	resp, err := cfg.buildChirpResponses(r, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error building chirp", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp[0])
+}
+
+func (cfg *apiConfig) handlerListUserLikes(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit, err := pagination.ParseLimit(query.Get("limit"), defaultChirpsPageSize, maxChirpsPageSize)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := cursorParams(query.Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	// Fetch one extra row so we know whether there is a next page
+	likes, err := cfg.dbQueries.ListLikedChirpsPage(r.Context(), database.ListLikedChirpsPageParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		ViewerID:        cfg.viewerNullID(r),
+		Limit:           int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error while getting likes from db", err)
+		return
+	}
+
+	if len(likes) > limit {
+		likes = likes[:limit]
+		last := likes[len(likes)-1]
+		setNextPageHeaders(w, r, pagination.EncodeCursor(pagination.Cursor{
+			CreatedAt: last.LikedAt,
+			ID:        last.Chirp.ID,
+		}))
+	}
+
+	liked := make([]database.Chirp, 0, len(likes))
+	for _, l := range likes {
+		liked = append(liked, l.Chirp)
+	}
+	chirps, err := cfg.buildChirpResponses(r, liked)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error building chirps", err)
+		return
+	}
+
+	response := make([]LikedChirpResponse, 0, len(likes))
+	for i, l := range likes {
+		response = append(response, LikedChirpResponse{
+			CreateChirpResponse: chirps[i],
+			LikedAt:             l.LikedAt.Format(time.RFC3339),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// viewerID returns the caller's user ID when the request carries a valid
+// bearer token. Endpoints that are public use it to personalise responses.
+func (cfg *apiConfig) viewerID(r *http.Request) (uuid.UUID, bool) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// setLikedByMe fills in liked_by_me on each chirp when the caller is
+// authenticated; anonymous requests leave the field out.
+func (cfg *apiConfig) setLikedByMe(r *http.Request, chirps []CreateChirpResponse) error {
+	viewerID, ok := cfg.viewerID(r)
+	if !ok || len(chirps) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(chirps))
+	for _, c := range chirps {
+		id, err := uuid.Parse(c.ID)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	likedIDs, err := cfg.dbQueries.ListLikedChirpIDs(r.Context(), database.ListLikedChirpIDsParams{
+		UserID:   viewerID,
+		ChirpIds: ids,
+	})
+	if err != nil {
+		return err
+	}
+
+	liked := make(map[string]bool, len(likedIDs))
+	for _, id := range likedIDs {
+		liked[id.String()] = true
+	}
+	for i := range chirps {
+		v := liked[chirps[i].ID]
+		chirps[i].LikedByMe = &v
+	}
+	return nil
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/moderation"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/pagination"
+)
+
+// openReportIndex is the partial unique index allowing one open report per
+// chirp and reporter.
+const openReportIndex = "chirp_reports_open_idx"
+
+type ReportRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details"`
+}
+
+type ReportResponse struct {
+	ID         string `json:"id"`
+	ChirpID    string `json:"chirp_id"`
+	ReporterID string `json:"reporter_id"`
+	Reason     string `json:"reason"`
+	Details    string `json:"details"`
+	CreatedAt  string `json:"created_at"`
+}
+
+func reportResponse(r database.ChirpReport) ReportResponse {
+	return ReportResponse{
+		ID:         r.ID.String(),
+		ChirpID:    r.ChirpID.String(),
+		ReporterID: r.ReporterID.String(),
+		Reason:     r.Reason,
+		Details:    r.Details,
+		CreatedAt:  r.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+type ReportedChirpResponse struct {
+	Chirp           CreateChirpResponse `json:"chirp"`
+	ReportCount     int64               `json:"report_count"`
+	Reasons         map[string]int      `json:"reasons"`
+	FirstReportedAt string              `json:"first_reported_at"`
+	Reports         []ReportResponse    `json:"reports"`
+}
+
+type ResolveReportsRequest struct {
+	Action string `json:"action"`
+	Note   string `json:"note"`
+}
+
+type ModerationActionResponse struct {
+	ID              string `json:"id"`
+	ChirpID         string `json:"chirp_id,omitempty"`
+	UserID          string `json:"user_id,omitempty"`
+	ActorID         string `json:"actor_id,omitempty"`
+	Action          string `json:"action"`
+	Note            string `json:"note"`
+	ReportsResolved int32  `json:"reports_resolved"`
+	CreatedAt       string `json:"created_at"`
+}
+
+func moderationActionResponse(a database.ModerationAction) ModerationActionResponse {
+	resp := ModerationActionResponse{
+		ID:              a.ID.String(),
+		Action:          a.Action,
+		Note:            a.Note,
+		ReportsResolved: a.ReportsResolved,
+		CreatedAt:       a.CreatedAt.Format(time.RFC3339),
+	}
+	if a.ChirpID.Valid {
+		resp.ChirpID = a.ChirpID.UUID.String()
+	}
+	if a.UserID.Valid {
+		resp.UserID = a.UserID.UUID.String()
+	}
+	if a.ActorID.Valid {
+		resp.ActorID = a.ActorID.UUID.String()
+	}
+	return resp
+}
+
+// handlerReportChirp files a report against someone else's chirp.
+func (cfg *apiConfig) handlerReportChirp(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	var params ReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	reason, err := moderation.ParseReason(params.Reason)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid reason", err)
+		return
+	}
+	details, err := moderation.ValidateDetails(reason, params.Details)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	chirp, err := cfg.dbQueries.GetChirpByID(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+		return
+	}
+	visible, err := cfg.chirpVisible(r.Context(), chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+		return
+	}
+	if !visible {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+		return
+	}
+	if chirp.UserID == userID {
+		respondWithError(w, http.StatusBadRequest, "You cannot report your own chirp", nil)
+		return
+	}
+
+	report, err := cfg.dbQueries.CreateChirpReport(r.Context(), database.CreateChirpReportParams{
+		ChirpID:    chirpID,
+		ReporterID: userID,
+		Reason:     string(reason),
+		Details:    details,
+	})
+	if err != nil {
+		if isUniqueViolation(err, openReportIndex) {
+			respondWithError(w, http.StatusConflict, "You have already reported this chirp", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save report", err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, reportResponse(report))
+}
+
+// handlerListReportedChirps returns chirps with open reports grouped by
+// chirp, longest-waiting first.
+func (cfg *apiConfig) handlerListReportedChirps(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit, err := pagination.ParseLimit(query.Get("limit"), defaultChirpsPageSize, maxChirpsPageSize)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := cursorParams(query.Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	// Fetch one extra row so we know whether there is a next page
+	rows, err := cfg.dbQueries.ListReportedChirpsPage(r.Context(), database.ListReportedChirpsPageParams{
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error while getting reports from db", err)
+		return
+	}
+
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		setNextPageHeaders(w, r, pagination.EncodeCursor(pagination.Cursor{
+			CreatedAt: last.FirstReportedAt,
+			ID:        last.Chirp.ID,
+		}))
+	}
+
+	reported := make([]database.Chirp, 0, len(rows))
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		reported = append(reported, row.Chirp)
+		ids = append(ids, row.Chirp.ID)
+	}
+	chirps, err := cfg.buildChirpResponses(r, reported)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error building chirps", err)
+		return
+	}
+
+	reports := map[uuid.UUID][]database.ChirpReport{}
+	if len(ids) > 0 {
+		all, err := cfg.dbQueries.ListOpenReportsByChirpIDs(r.Context(), ids)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error while getting reports from db", err)
+			return
+		}
+		for _, report := range all {
+			reports[report.ChirpID] = append(reports[report.ChirpID], report)
+		}
+	}
+
+	response := make([]ReportedChirpResponse, 0, len(rows))
+	for i, row := range rows {
+		item := ReportedChirpResponse{
+			Chirp:           chirps[i],
+			ReportCount:     row.ReportCount,
+			Reasons:         map[string]int{},
+			FirstReportedAt: row.FirstReportedAt.Format(time.RFC3339),
+			Reports:         []ReportResponse{},
+		}
+		for _, report := range reports[row.Chirp.ID] {
+			item.Reasons[report.Reason]++
+			item.Reports = append(item.Reports, reportResponse(report))
+		}
+		response = append(response, item)
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// handlerResolveReports closes every open report on a chirp with one action
+// and records it in the moderation log.
+func (cfg *apiConfig) handlerResolveReports(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return
+	}
+
+	var params ResolveReportsRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	action, err := moderation.ParseAction(params.Action)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid action", err)
+		return
+	}
+	note, err := moderation.ValidateNote(params.Note)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	actorID, ok := cfg.moderatorID(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	chirp, err := qtx.GetChirpByIDForUpdate(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+		return
+	}
+
+	resolved, err := qtx.ResolveChirpReports(r.Context(), database.ResolveChirpReportsParams{
+		ChirpID:    chirpID,
+		Resolution: sql.NullString{String: string(action), Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve reports", err)
+		return
+	}
+	if resolved == 0 {
+		respondWithError(w, http.StatusNotFound, "Chirp has no open reports", nil)
+		return
+	}
+
+	// Suspending the author takes the reported chirp down too
+	if action == moderation.ActionHide || action == moderation.ActionSuspend {
+		if err := qtx.HideChirp(r.Context(), chirp.ID); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't hide chirp", err)
+			return
+		}
+	}
+	if action == moderation.ActionSuspend {
+		if !cfg.checkOutranks(w, r, actorID, chirp.UserID) {
+			return
+		}
+		_, err := qtx.SuspendUser(r.Context(), database.SuspendUserParams{
+			ID:               chirp.UserID,
+			SuspensionReason: note,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't suspend user", err)
+			return
+		}
+		if err := qtx.RevokeUserRefreshTokens(r.Context(), chirp.UserID); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+			return
+		}
+	}
+
+	logged, err := qtx.CreateModerationAction(r.Context(), database.CreateModerationActionParams{
+		ChirpID:         uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		UserID:          uuid.NullUUID{UUID: chirp.UserID, Valid: true},
+		ActorID:         uuid.NullUUID{UUID: actorID, Valid: true},
+		Action:          string(action),
+		Note:            note,
+		ReportsResolved: int32(resolved),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record moderation action", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve reports", err)
+		return
+	}
+	if action == moderation.ActionSuspend {
+		cfg.authUsers.Forget(chirp.UserID)
+	}
+	respondWithJSON(w, http.StatusOK, moderationActionResponse(logged))
+}
+
+// handlerListModerationActions returns the moderation log, newest first.
+func (cfg *apiConfig) handlerListModerationActions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit, err := pagination.ParseLimit(query.Get("limit"), defaultChirpsPageSize, maxChirpsPageSize)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := cursorParams(query.Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	actions, err := cfg.dbQueries.ListModerationActionsPage(r.Context(), database.ListModerationActionsPageParams{
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error while getting moderation actions from db", err)
+		return
+	}
+
+	if len(actions) > limit {
+		actions = actions[:limit]
+		last := actions[len(actions)-1]
+		setNextPageHeaders(w, r, pagination.EncodeCursor(pagination.Cursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		}))
+	}
+
+	response := make([]ModerationActionResponse, 0, len(actions))
+	for _, a := range actions {
+		response = append(response, moderationActionResponse(a))
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
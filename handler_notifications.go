@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/entities"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/pagination"
+)
+
+const (
+	notificationMention = "mention"
+	notificationReply   = "reply"
+	notificationLike    = "like"
+	notificationFollow  = "follow"
+)
+
+type NotificationResponse struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	ActorID   string `json:"actor_id"`
+	ChirpID   string `json:"chirp_id,omitempty"`
+	CreatedAt string `json:"created_at"`
+	Read      bool   `json:"read"`
+	ReadAt    string `json:"read_at,omitempty"`
+}
+
+type NotificationsResponse struct {
+	UnreadCount int64                  `json:"unread_count"`
+	Items       []NotificationResponse `json:"notifications"`
+}
+
+// notify records a notification for userID unless the actor is notifying
+// themselves. Pass a transaction-bound q so it is only kept if the action is.
+func notify(ctx context.Context, q *database.Queries, userID, actorID uuid.UUID, kind string, chirpID uuid.NullUUID) error {
+	if userID == actorID {
+		return nil
+	}
+	return q.CreateNotification(ctx, database.CreateNotificationParams{
+		UserID:  userID,
+		ActorID: actorID,
+		Kind:    kind,
+		ChirpID: chirpID,
+	})
+}
+
+// notifyChirpCreated tells the author of the chirp being replied to, and every
+// user mentioned in the body, about a new chirp. Someone who is both replied
+// to and mentioned only gets the reply notification.
+func notifyChirpCreated(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	chirpID := uuid.NullUUID{UUID: chirp.ID, Valid: true}
+	notified := map[uuid.UUID]bool{}
+
+	if chirp.ParentID.Valid {
+		parent, err := q.GetChirpByID(ctx, chirp.ParentID.UUID)
+		if err != nil {
+			return err
+		}
+		if err := notify(ctx, q, parent.UserID, chirp.UserID, notificationReply, chirpID); err != nil {
+			return err
+		}
+		notified[parent.UserID] = true
+	}
+
+	mentions := entities.ExtractMentions(chirp.Body)
+	if len(mentions) == 0 {
+		return nil
+	}
+	users, err := q.ListUsersByUsernames(ctx, mentions)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		if notified[u.ID] {
+			continue
+		}
+		if err := notify(ctx, q, u.ID, chirp.UserID, notificationMention, chirpID); err != nil {
+			return err
+		}
+		notified[u.ID] = true
+	}
+	return nil
+}
+
+func notificationResponse(n database.Notification) NotificationResponse {
+	resp := NotificationResponse{
+		ID:        n.ID.String(),
+		Kind:      n.Kind,
+		ActorID:   n.ActorID.String(),
+		CreatedAt: n.CreatedAt.Format(time.RFC3339),
+		Read:      n.ReadAt.Valid,
+	}
+	if n.ChirpID.Valid {
+		resp.ChirpID = n.ChirpID.UUID.String()
+	}
+	if n.ReadAt.Valid {
+		resp.ReadAt = n.ReadAt.Time.Format(time.RFC3339)
+	}
+	return resp
+}
+
+// handlerListNotifications returns the caller's notifications, newest first,
+// along with how many are still unread. ?unread=true limits the page to
+// unread ones.
+func (cfg *apiConfig) handlerListNotifications(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit, err := pagination.ParseLimit(query.Get("limit"), defaultChirpsPageSize, maxChirpsPageSize)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	unreadOnly := false
+	if unreadStr := query.Get("unread"); unreadStr != "" {
+		unreadOnly, err = strconv.ParseBool(unreadStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid unread flag", err)
+			return
+		}
+	}
+
+	cursorCreatedAt, cursorID, err := cursorParams(query.Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	// Fetch one extra row so we know whether there is a next page
+	notifications, err := cfg.dbQueries.ListNotificationsPage(r.Context(), database.ListNotificationsPageParams{
+		UserID:          userID,
+		UnreadOnly:      unreadOnly,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error while getting notifications from db", err)
+		return
+	}
+
+	unread, err := cfg.dbQueries.CountUnreadNotifications(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error counting notifications", err)
+		return
+	}
+
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+		last := notifications[len(notifications)-1]
+		setNextPageHeaders(w, r, pagination.EncodeCursor(pagination.Cursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		}))
+	}
+
+	resp := NotificationsResponse{
+		UnreadCount: unread,
+		Items:       make([]NotificationResponse, 0, len(notifications)),
+	}
+	for _, n := range notifications {
+		resp.Items = append(resp.Items, notificationResponse(n))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handlerMarkNotificationsRead marks the notifications listed in "ids" as
+// read, or all of the caller's notifications when the body has no ids.
+func (cfg *apiConfig) handlerMarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	var params struct {
+		IDs []string `json:"ids"`
+	}
+	// The body is optional; an empty one means "mark everything read"
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	var ids []uuid.UUID
+	for _, idStr := range params.IDs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid notification ID", err)
+			return
+		}
+		ids = append(ids, id)
+	}
+	if params.IDs != nil && len(ids) == 0 {
+		// An explicit empty list marks nothing
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	_, err = cfg.dbQueries.MarkNotificationsRead(r.Context(), database.MarkNotificationsReadParams{
+		UserID: userID,
+		Ids:    ids,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't mark notifications read", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
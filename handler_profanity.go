@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/pagination"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/profanity"
+)
+
+type ProfanityWordsResponse struct {
+	Mode  profanity.Mode `json:"mode"`
+	Words []string       `json:"words"`
+}
+
+type FlaggedChirpResponse struct {
+	CreateChirpResponse
+	FlaggedWords []string `json:"flagged_words"`
+	FlaggedAt    string   `json:"flagged_at"`
+}
+
+// dbWordStore keeps the profanity list in the profanity_words table.
+type dbWordStore struct {
+	q *database.Queries
+}
+
+func (s dbWordStore) Load(ctx context.Context) ([]string, error) {
+	return s.q.ListProfanityWords(ctx)
+}
+
+func (s dbWordStore) Add(ctx context.Context, word string) (bool, error) {
+	rows, err := s.q.CreateProfanityWord(ctx, word)
+	return rows > 0, err
+}
+
+func (s dbWordStore) Remove(ctx context.Context, word string) (bool, error) {
+	rows, err := s.q.DeleteProfanityWord(ctx, word)
+	return rows > 0, err
+}
+
+// saveProfanityFlag flags a chirp for review when the filter runs in flag
+// mode and the body has listed words, and clears the flag once it has none.
+// Pass a transaction-bound q so the flag changes with the chirp.
+func (cfg *apiConfig) saveProfanityFlag(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	if cfg.profanityMode != profanity.ModeFlag {
+		return nil
+	}
+	matches := cfg.profanity.Find(chirp.Body)
+	if len(matches) == 0 {
+		_, err := q.DeleteChirpProfanityFlag(ctx, chirp.ID)
+		return err
+	}
+
+	words := []string{}
+	seen := map[string]bool{}
+	for _, m := range matches {
+		if !seen[m.Word] {
+			seen[m.Word] = true
+			words = append(words, m.Word)
+		}
+	}
+	return q.UpsertChirpProfanityFlag(ctx, database.UpsertChirpProfanityFlagParams{
+		ChirpID: chirp.ID,
+		Words:   words,
+	})
+}
+
+func (cfg *apiConfig) handlerListProfanityWords(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, ProfanityWordsResponse{
+		Mode:  cfg.profanityMode,
+		Words: cfg.profanity.Words(),
+	})
+}
+
+func (cfg *apiConfig) handlerAddProfanityWord(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Word string `json:"word"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	word := strings.ToLower(strings.TrimSpace(params.Word))
+	if strings.ContainsFunc(word, unicode.IsSpace) || profanity.Normalize(word) == "" {
+		respondWithError(w, http.StatusBadRequest, "A word must be a single word with at least one letter or digit", nil)
+		return
+	}
+
+	if _, err := cfg.profanityStore.Add(r.Context(), word); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't add word", err)
+		return
+	}
+	cfg.reloadProfanity(w, r)
+}
+
+func (cfg *apiConfig) handlerDeleteProfanityWord(w http.ResponseWriter, r *http.Request) {
+	word := strings.ToLower(strings.TrimSpace(r.PathValue("word")))
+
+	removed, err := cfg.profanityStore.Remove(r.Context(), word)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't remove word", err)
+		return
+	}
+	if !removed {
+		respondWithError(w, http.StatusNotFound, "Word is not listed", nil)
+		return
+	}
+	cfg.reloadProfanity(w, r)
+}
+
+// reloadProfanity swaps the stored list into the running filter, so edits
+// apply to the next chirp without a restart, and responds with the new list.
+func (cfg *apiConfig) reloadProfanity(w http.ResponseWriter, r *http.Request) {
+	words, err := cfg.profanityStore.Load(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reload word list", err)
+		return
+	}
+	cfg.profanity.SetWords(words)
+	cfg.handlerListProfanityWords(w, r)
+}
+
+// handlerListProfanityFlags returns chirps flagged for review, oldest first.
+func (cfg *apiConfig) handlerListProfanityFlags(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit, err := pagination.ParseLimit(query.Get("limit"), defaultChirpsPageSize, maxChirpsPageSize)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := cursorParams(query.Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	// Fetch one extra row so we know whether there is a next page
+	flags, err := cfg.dbQueries.ListChirpProfanityFlagsPage(r.Context(), database.ListChirpProfanityFlagsPageParams{
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error while getting flagged chirps from db", err)
+		return
+	}
+
+	if len(flags) > limit {
+		flags = flags[:limit]
+		last := flags[len(flags)-1]
+		setNextPageHeaders(w, r, pagination.EncodeCursor(pagination.Cursor{
+			CreatedAt: last.FlaggedAt,
+			ID:        last.Chirp.ID,
+		}))
+	}
+
+	flagged := make([]database.Chirp, 0, len(flags))
+	for _, f := range flags {
+		flagged = append(flagged, f.Chirp)
+	}
+	chirps, err := cfg.buildChirpResponses(r, flagged)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error building chirps", err)
+		return
+	}
+
+	response := make([]FlaggedChirpResponse, 0, len(flags))
+	for i, f := range flags {
+		response = append(response, FlaggedChirpResponse{
+			CreateChirpResponse: chirps[i],
+			FlaggedWords:        f.Words,
+			FlaggedAt:           f.FlaggedAt.Format(time.RFC3339),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// handlerClearProfanityFlag marks a flagged chirp as reviewed.
+func (cfg *apiConfig) handlerClearProfanityFlag(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return
+	}
+
+	rows, err := cfg.dbQueries.DeleteChirpProfanityFlag(r.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't clear flag", err)
+		return
+	}
+	if rows == 0 {
+		respondWithError(w, http.StatusNotFound, "Chirp is not flagged", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
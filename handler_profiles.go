@@ -0,0 +1,106 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/profile"
+)
+
+// usernameIndex is the unique index that keeps usernames distinct regardless
+// of case.
+const usernameIndex = "users_username_lower_idx"
+
+// ProfileResponse is the public view of a user; it never carries the email.
+type ProfileResponse struct {
+	ID          string `json:"id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	AvatarURL   string `json:"avatar_url"`
+	IsChirpyRed bool   `json:"is_chirpy_red"`
+	CreatedAt   string `json:"created_at"`
+	ChirpCount  int64  `json:"chirp_count"`
+}
+
+// AuthorResponse is the slice of a profile embedded in chirps.
+type AuthorResponse struct {
+	ID          string `json:"id"`
+	Username    string `json:"username,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+}
+
+func authorResponse(user database.User) AuthorResponse {
+	return AuthorResponse{
+		ID:          user.ID.String(),
+		Username:    user.Username.String,
+		DisplayName: user.DisplayName,
+		AvatarURL:   user.AvatarUrl,
+	}
+}
+
+// validateProfile checks the user-editable profile fields. An empty username
+// means the account has none.
+func validateProfile(username, displayName, bio, avatarURL string) error {
+	if username != "" {
+		if err := profile.ValidateUsername(username); err != nil {
+			return err
+		}
+	}
+	if err := profile.ValidateDisplayName(displayName); err != nil {
+		return err
+	}
+	if err := profile.ValidateBio(bio); err != nil {
+		return err
+	}
+	return profile.ValidateAvatarURL(avatarURL)
+}
+
+// isUniqueViolation reports whether err is Postgres rejecting a duplicate in
+// the given unique constraint or index.
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
+
+func (cfg *apiConfig) handlerGetUserProfile(w http.ResponseWriter, r *http.Request) {
+	username := r.PathValue("username")
+
+	user, err := cfg.dbQueries.GetUserByUsername(r.Context(), username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "User not found", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "DB error", err)
+		return
+	}
+	// Profiles of accounts that are switched off are gone along with their chirps
+	if checkAccount(user) != nil {
+		respondWithError(w, http.StatusNotFound, "User not found", nil)
+		return
+	}
+
+	chirpCount, err := cfg.dbQueries.CountChirpsByUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error counting chirps", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ProfileResponse{
+		ID:          user.ID.String(),
+		Username:    user.Username.String,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarUrl,
+		IsChirpyRed: user.IsChirpyRed,
+		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
+		ChirpCount:  chirpCount,
+	})
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+)
+
+const (
+	repostKindRechirp = "rechirp"
+	repostKindQuote   = "quote"
+)
+
+// handlerRechirp reposts the chirp in the path. An empty body is a plain
+// rechirp; a body turns it into a quote-chirp, which goes through the same
+// checks as any other chirp body.
+func (cfg *apiConfig) handlerRechirp(w http.ResponseWriter, r *http.Request) {
+	originalID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Chirp ID", err)
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	if !cfg.checkCanPost(w, r, userID) {
+		return
+	}
+
+	// The body is optional, so an empty request is fine
+	var params CreateChirpRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	original, err := cfg.dbQueries.GetChirpByID(r.Context(), originalID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+		return
+	}
+
+	// Rechirping a rechirp points at what it rechirped
+	if original.RepostKind.String == repostKindRechirp {
+		if !original.OriginalID.Valid {
+			respondWithError(w, http.StatusNotFound, "Original chirp has been deleted", nil)
+			return
+		}
+		originalID = original.OriginalID.UUID
+		original, err = cfg.dbQueries.GetChirpByID(r.Context(), originalID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+			return
+		}
+	}
+
+	visible, err := cfg.chirpVisible(r.Context(), original)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+		return
+	}
+	if !visible {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+		return
+	}
+
+	blocked, err := cfg.isBlocked(r.Context(), userID, original.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error checking blocks", err)
+		return
+	}
+	if blocked {
+		respondWithError(w, http.StatusForbidden, "You cannot rechirp this user", nil)
+		return
+	}
+
+	kind := repostKindRechirp
+	body := ""
+	if params.Body != "" {
+		kind = repostKindQuote
+		body, err = cfg.cleanChirpBody(params.Body)
+		if err != nil {
+			respondWithChirpError(w, err)
+			return
+		}
+		if !cfg.checkMentions(w, r, userID, body) {
+			return
+		}
+	}
+
+	chirp, err := cfg.createChirp(r.Context(), database.CreateChirpParams{
+		Body:       body,
+		UserID:     userID,
+		OriginalID: uuid.NullUUID{UUID: originalID, Valid: true},
+		RepostKind: sql.NullString{String: kind, Valid: true},
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			respondWithError(w, http.StatusConflict, "You have already rechirped this chirp", nil)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
+		return
+	}
+
+	resp, err := cfg.buildChirpResponses(r, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error building chirp", err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, resp[0])
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/pagination"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/search"
+)
+
+type SearchChirpResponse struct {
+	CreateChirpResponse
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+func (cfg *apiConfig) handlerSearchChirps(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	tsQuery, err := search.BuildTSQuery(query.Get("q"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid search query", err)
+		return
+	}
+
+	limit, err := pagination.ParseLimit(query.Get("limit"), defaultChirpsPageSize, maxChirpsPageSize)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	offset := 0
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err = strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid offset", err)
+			return
+		}
+	}
+
+	// Optional filters
+	var authorID uuid.NullUUID
+	if authorIDStr := query.Get("author_id"); authorIDStr != "" {
+		authorUUID, err := uuid.Parse(authorIDStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid author ID format", err)
+			return
+		}
+		authorID = uuid.NullUUID{UUID: authorUUID, Valid: true}
+	}
+
+	since, err := parseTimeParam(query.Get("since"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid since timestamp, expected RFC3339", err)
+		return
+	}
+	until, err := parseTimeParam(query.Get("until"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid until timestamp, expected RFC3339", err)
+		return
+	}
+
+	results, err := cfg.dbQueries.SearchChirps(r.Context(), database.SearchChirpsParams{
+		Query:    tsQuery,
+		AuthorID: authorID,
+		Since:    since,
+		Until:    until,
+		ViewerID: cfg.viewerNullID(r),
+		Limit:    int32(limit),
+		Offset:   int32(offset),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error while searching chirps", err)
+		return
+	}
+
+	chirps := make([]database.Chirp, 0, len(results))
+	for _, res := range results {
+		chirps = append(chirps, res.Chirp)
+	}
+	built, err := cfg.buildChirpResponses(r, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error building chirps", err)
+		return
+	}
+	if err := cfg.applyUserFilters(r, built); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error applying filters", err)
+		return
+	}
+
+	response := make([]SearchChirpResponse, 0, len(results))
+	for i, res := range results {
+		response = append(response, SearchChirpResponse{
+			CreateChirpResponse: built[i],
+			Rank:                res.Rank,
+			Snippet:             res.Snippet,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// parseTimeParam parses an optional RFC3339 query parameter.
+func parseTimeParam(s string) (sql.NullTime, error) {
+	if s == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}, nil
+}
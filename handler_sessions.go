@@ -0,0 +1,187 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+)
+
+// maxUserAgentLength caps how much of a client's User-Agent is kept.
+const maxUserAgentLength = 512
+
+type SessionResponse struct {
+	ID         string `json:"id"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	ExpiresAt  string `json:"expires_at"`
+	UserAgent  string `json:"user_agent"`
+	IPAddress  string `json:"ip_address"`
+	Current    bool   `json:"current"`
+}
+
+// handlerListSessions lists the caller's signed-in sessions, most recently
+// used first. A session lasts from login until its refresh token is revoked
+// or expires, however many times the token is rotated in between.
+func (cfg *apiConfig) handlerListSessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := cfg.sessionClaims(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := claims.UserID()
+
+	rows, err := cfg.dbQueries.ListUserSessions(r.Context(), database.ListUserSessionsParams{
+		UserID:    userID,
+		ExpiresAt: time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't list sessions", err)
+		return
+	}
+
+	sessions := make([]SessionResponse, 0, len(rows))
+	for _, row := range rows {
+		sessions = append(sessions, SessionResponse{
+			ID:         row.FamilyID.String(),
+			CreatedAt:  row.StartedAt.Format(time.RFC3339),
+			LastUsedAt: row.LastUsedAt.Format(time.RFC3339),
+			ExpiresAt:  row.ExpiresAt.Format(time.RFC3339),
+			UserAgent:  row.UserAgent,
+			IPAddress:  row.IpAddress,
+			Current:    row.FamilyID == claims.Session(),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, sessions)
+}
+
+// handlerRevokeSession signs one of the caller's sessions out, along with the
+// access tokens issued to it.
+func (cfg *apiConfig) handlerRevokeSession(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := uuid.Parse(r.PathValue("sessionID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid session ID", err)
+		return
+	}
+
+	claims, ok := cfg.sessionClaims(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := claims.UserID()
+
+	rows, err := cfg.dbQueries.RevokeUserSession(r.Context(), database.RevokeUserSessionParams{
+		FamilyID: sessionID,
+		UserID:   userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke session", err)
+		return
+	}
+	if rows == 0 {
+		respondWithError(w, http.StatusNotFound, "Session not found", nil)
+		return
+	}
+	cfg.authSessions.Forget(sessionID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerRevokeOtherSessions signs the caller out everywhere except the
+// session their access token came from. Access tokens that name no session
+// can only be revoked by moving the token version on, which takes the
+// caller's own with them, so it answers with a fresh access token for the
+// session that stays. Other server instances may accept the revoked tokens
+// until their cached state expires.
+func (cfg *apiConfig) handlerRevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := cfg.sessionClaims(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := claims.UserID()
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	revoked, err := qtx.RevokeOtherUserSessions(r.Context(), database.RevokeOtherUserSessionsParams{
+		UserID:       userID,
+		KeepFamilyID: claims.Session(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+		return
+	}
+	if err := qtx.BumpUserTokenVersion(r.Context(), userID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+		return
+	}
+	user, err := qtx.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+		return
+	}
+	accessToken, err := auth.MakeJWT(user.ID, auth.Role(user.Role), claims.Session(), user.TokenVersion, cfg.jwtKeys, time.Hour)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create JWT", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+		return
+	}
+
+	cfg.authUsers.Forget(userID)
+	// A session refreshed concurrently can have two live tokens, so a family
+	// may come back twice; forgetting it again is harmless
+	for _, familyID := range revoked {
+		cfg.authSessions.Forget(familyID)
+	}
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"token": accessToken,
+	})
+}
+
+// sessionClaims authenticates the request, writing a 401 if it can't.
+func (cfg *apiConfig) sessionClaims(w http.ResponseWriter, r *http.Request) (*auth.Claims, bool) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing token", err)
+		return nil, false
+	}
+
+	claims, err := auth.ParseClaims(tokenString, cfg.jwtKeys)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return nil, false
+	}
+	return claims, true
+}
+
+// clientIP returns the address the request came from. Forwarding headers are
+// ignored since nothing vouches for them.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,75 @@
+package account
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Status is where an account is in its lifecycle.
+type Status string
+
+const (
+	StatusActive Status = "active"
+	// StatusDeactivated accounts were switched off by their owner, who can
+	// switch them back on.
+	StatusDeactivated Status = "deactivated"
+	// StatusSuspended accounts were switched off by an admin, either until a
+	// set time or indefinitely.
+	StatusSuspended Status = "suspended"
+	// StatusDeleted accounts are waiting out their deletion grace period.
+	StatusDeleted Status = "deleted"
+)
+
+var (
+	ErrDeactivated = errors.New("account is deactivated")
+	ErrSuspended   = errors.New("account is suspended")
+	ErrDeleted     = errors.New("account is deleted")
+)
+
+// SuspendedError is returned for a suspended account, saying why and for how
+// long. It matches ErrSuspended.
+type SuspendedError struct {
+	Reason string
+	// Until is zero for an indefinite suspension.
+	Until time.Time
+}
+
+func (e *SuspendedError) Error() string {
+	msg := "account is suspended"
+	if !e.Until.IsZero() {
+		msg += " until " + e.Until.Format(time.RFC3339)
+	}
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+func (e *SuspendedError) Is(target error) bool {
+	return target == ErrSuspended
+}
+
+// Check returns nil if an account may use the API at now, or an error saying
+// why not. A suspension with an expiry lapses once now passes it.
+func Check(status string, reason string, suspendedUntil sql.NullTime, now time.Time) error {
+	switch Status(status) {
+	case StatusActive:
+		return nil
+	case StatusDeactivated:
+		return ErrDeactivated
+	case StatusDeleted:
+		return ErrDeleted
+	case StatusSuspended:
+		if suspendedUntil.Valid && !now.Before(suspendedUntil.Time) {
+			return nil
+		}
+		err := &SuspendedError{Reason: reason}
+		if suspendedUntil.Valid {
+			err.Until = suspendedUntil.Time
+		}
+		return err
+	}
+	return fmt.Errorf("unknown account status %q", status)
+}
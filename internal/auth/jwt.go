@@ -8,20 +8,39 @@ import (
 	"github.com/google/uuid"
 )
 
-func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (string, error) {
+// Claims are the claims in a Chirpy access token.
+type Claims struct {
+	jwt.RegisteredClaims
+	Role Role `json:"role,omitempty"`
+	// SessionID is the refresh token family the token was issued from.
+	SessionID string `json:"sid,omitempty"`
+	// Version is the user's token version when the token was issued. Tokens
+	// from before the user's current version have been revoked.
+	Version int32 `json:"ver,omitempty"`
+}
+
+// MakeJWT issues an access token signed with the keyring's active key, with a
+// unique jti. sessionID may be uuid.Nil for a token that doesn't belong to a
+// session; version is the user's current token version.
+func MakeJWT(userID uuid.UUID, role Role, sessionID uuid.UUID, version int32, keys *Keyring, expiresIn time.Duration) (string, error) {
 	// Build claims
-	claims := jwt.RegisteredClaims{
-		Issuer:    "chirpy",
-		IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
-		ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(expiresIn)),
-		Subject:   userID.String(),
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "chirpy",
+			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(expiresIn)),
+			Subject:   userID.String(),
+			ID:        uuid.NewString(),
+		},
+		Role:    role,
+		Version: version,
+	}
+	if sessionID != uuid.Nil {
+		claims.SessionID = sessionID.String()
 	}
 
-	// Create token using HS256
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign token using the provided secret
-	signed, err := token.SignedString([]byte(tokenSecret))
+	// Sign token with the active key
+	signed, err := keys.sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -29,36 +48,60 @@ func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (str
 	return signed, nil
 }
 
-func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
+func ValidateJWT(tokenString string, keys *Keyring) (uuid.UUID, error) {
+	userID, _, err := ParseJWT(tokenString, keys)
+	return userID, err
+}
+
+// ParseJWT validates a token and returns its user ID and role. Tokens issued
+// before roles existed carry no role claim and count as RoleUser.
+func ParseJWT(tokenString string, keys *Keyring) (uuid.UUID, Role, error) {
+	claims, err := ParseClaims(tokenString, keys)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	userID, _ := claims.UserID()
+	return userID, claims.Role, nil
+}
+
+// UserID returns the user the token was issued to.
+func (c *Claims) UserID() (uuid.UUID, error) {
+	return uuid.Parse(c.Subject)
+}
+
+// Session returns the session the token was issued from, or uuid.Nil if it
+// has none.
+func (c *Claims) Session() uuid.UUID {
+	id, err := uuid.Parse(c.SessionID)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+// ParseClaims validates a token against the keyring and returns all of its
+// claims.
+func ParseClaims(tokenString string, keys *Keyring) (*Claims, error) {
 	// Prepare a place to store claims
-	claims := &jwt.RegisteredClaims{}
+	claims := &Claims{}
 
 	// Parse & validate the token
-	token, err := jwt.ParseWithClaims(
-		tokenString,
-		claims,
-		func(t *jwt.Token) (interface{}, error) {
-			// Must match the signing method used in MakeJWT
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return []byte(tokenSecret), nil
-		},
-	)
-
+	token, err := jwt.ParseWithClaims(tokenString, claims, keys.keyFunc)
 	if err != nil {
-		return uuid.Nil, err // bad signature, expired, malformed, etc.
+		return nil, err // bad signature, expired, malformed, etc.
 	}
 
 	if !token.Valid {
-		return uuid.Nil, errors.New("invalid token")
+		return nil, errors.New("invalid token")
 	}
 
-	// Extract user id from claims.Subject
-	userID, err := uuid.Parse(claims.Subject)
-	if err != nil {
-		return uuid.Nil, errors.New("invalid subject UUID")
+	// The subject must be a user id
+	if _, err := claims.UserID(); err != nil {
+		return nil, errors.New("invalid subject UUID")
 	}
 
-	return userID, nil
+	if claims.Role == "" {
+		claims.Role = RoleUser
+	}
+	return claims, nil
 }
@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algorithm is a JWT signing algorithm.
+type Algorithm string
+
+const (
+	AlgHS256 Algorithm = "HS256"
+	AlgRS256 Algorithm = "RS256"
+	AlgEdDSA Algorithm = "EdDSA"
+)
+
+var (
+	ErrUnknownKey = errors.New("unknown signing key")
+	ErrRetiredKey = errors.New("signing key has been retired")
+)
+
+// Key is one signing key in a Keyring. An asymmetric key loaded from a
+// public key can only verify.
+type Key struct {
+	// ID goes in the kid header of tokens the key signs. Tokens without a
+	// kid are checked against the key with an empty ID.
+	ID        string
+	Algorithm Algorithm
+	// RetiredAt is when the key stopped signing. Its tokens are accepted for
+	// the keyring's grace period afterwards. Zero for keys still in use.
+	RetiredAt time.Time
+
+	sign   interface{}
+	verify interface{}
+}
+
+// NewHMACKey returns an HS256 key.
+func NewHMACKey(id string, secret []byte) Key {
+	return Key{ID: id, Algorithm: AlgHS256, sign: secret, verify: secret}
+}
+
+// ParseKeyPEM reads an RS256 or EdDSA key from PEM. A private key can sign
+// and verify; a public key can only verify.
+func ParseKeyPEM(id string, alg Algorithm, data []byte) (Key, error) {
+	key := Key{ID: id, Algorithm: alg}
+	switch alg {
+	case AlgRS256:
+		if priv, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			key.sign, key.verify = priv, &priv.PublicKey
+		} else if pub, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+			key.verify = pub
+		} else {
+			return Key{}, fmt.Errorf("key %q: not an RSA key in PEM", id)
+		}
+	case AlgEdDSA:
+		if priv, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+			edPriv := priv.(ed25519.PrivateKey)
+			key.sign, key.verify = edPriv, edPriv.Public()
+		} else if pub, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+			key.verify = pub
+		} else {
+			return Key{}, fmt.Errorf("key %q: not an Ed25519 key in PEM", id)
+		}
+	default:
+		return Key{}, fmt.Errorf("key %q: unsupported algorithm %q", id, alg)
+	}
+	return key, nil
+}
+
+func (k Key) method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// Keyring signs access tokens with its active key and verifies them with any
+// key it holds, so keys can be rotated without signing everyone out.
+type Keyring struct {
+	active Key
+	keys   map[string]Key
+	grace  time.Duration
+}
+
+// NewKeyring builds a keyring that signs with active. The other keys are
+// accepted for verification: retired ones for grace after their RetiredAt,
+// the rest indefinitely, which lets a key be published before it is used.
+func NewKeyring(active Key, grace time.Duration, others ...Key) (*Keyring, error) {
+	if active.sign == nil {
+		return nil, fmt.Errorf("key %q: active key has no private key", active.ID)
+	}
+	if !active.RetiredAt.IsZero() {
+		return nil, fmt.Errorf("key %q: active key is retired", active.ID)
+	}
+	k := &Keyring{active: active, keys: map[string]Key{active.ID: active}, grace: grace}
+	for _, key := range others {
+		if _, ok := k.keys[key.ID]; ok {
+			return nil, fmt.Errorf("key %q: duplicate key ID", key.ID)
+		}
+		k.keys[key.ID] = key
+	}
+	return k, nil
+}
+
+// sign signs claims with the active key.
+func (k *Keyring) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.active.method(), claims)
+	if k.active.ID != "" {
+		token.Header["kid"] = k.active.ID
+	}
+	return token.SignedString(k.active.sign)
+}
+
+// keyFunc picks the key a token claims to be signed with, refusing it if the
+// key is unknown, retired too long ago, or of a different algorithm than the
+// token's header says.
+func (k *Keyring) keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if !key.RetiredAt.IsZero() && time.Now().After(key.RetiredAt.Add(k.grace)) {
+		return nil, ErrRetiredKey
+	}
+	if t.Method.Alg() != string(key.Algorithm) {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.verify, nil
+}
+
+// JWK is a public key in JSON Web Key form.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public half of every asymmetric key that tokens can
+// currently be verified with. HMAC keys are secrets and are never included.
+func (k *Keyring) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	now := time.Now()
+	for _, key := range k.keys {
+		if !key.RetiredAt.IsZero() && now.After(key.RetiredAt.Add(k.grace)) {
+			continue
+		}
+		jwk := JWK{KeyID: key.ID, Algorithm: string(key.Algorithm), Use: "sig"}
+		switch pub := key.verify.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// keyFile is the JSON layout read by LoadKeyring.
+type keyFile struct {
+	Active string `json:"active"`
+	Keys   []struct {
+		ID        string    `json:"kid"`
+		Algorithm Algorithm `json:"alg"`
+		// Secret is an HS256 key; KeyFile is a PEM file for the others,
+		// relative to the key file.
+		Secret    string    `json:"secret"`
+		KeyFile   string    `json:"key_file"`
+		RetiredAt time.Time `json:"retired_at"`
+	} `json:"keys"`
+}
+
+// LoadKeyring reads a keyring from a JSON file listing the keys and the ID of
+// the active one. extra keys, such as one for tokens issued before key IDs
+// existed, are added for verification.
+func LoadKeyring(path string, grace time.Duration, extra ...Key) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file keyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var active *Key
+	var others []Key
+	for _, entry := range file.Keys {
+		if entry.ID == "" {
+			return nil, fmt.Errorf("%s: every key needs a kid", path)
+		}
+		var key Key
+		if entry.Algorithm == AlgHS256 {
+			if entry.Secret == "" {
+				return nil, fmt.Errorf("key %q: HS256 needs a secret", entry.ID)
+			}
+			key = NewHMACKey(entry.ID, []byte(entry.Secret))
+		} else {
+			keyPath := entry.KeyFile
+			if !filepath.IsAbs(keyPath) {
+				keyPath = filepath.Join(filepath.Dir(path), keyPath)
+			}
+			pem, err := os.ReadFile(keyPath)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", entry.ID, err)
+			}
+			if key, err = ParseKeyPEM(entry.ID, entry.Algorithm, pem); err != nil {
+				return nil, err
+			}
+		}
+		key.RetiredAt = entry.RetiredAt
+
+		if entry.ID == file.Active {
+			active = &key
+		} else {
+			others = append(others, key)
+		}
+	}
+	if active == nil {
+		return nil, fmt.Errorf("%s: active key %q not found", path, file.Active)
+	}
+	return NewKeyring(*active, grace, append(others, extra...)...)
+}
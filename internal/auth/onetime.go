@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// MakeOneTimeToken returns a random token to send to a user, for password
+// resets and the like, along with the hash to store in its place.
+func MakeOneTimeToken() (token, hash string, err error) {
+	token, err = MakeRefreshToken()
+	if err != nil {
+		return "", "", err
+	}
+	return token, HashOneTimeToken(token), nil
+}
+
+// HashOneTimeToken hashes a token a user presented so it can be looked up.
+// The tokens are random, so a plain SHA-256 is enough.
+func HashOneTimeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,114 @@
+package authcache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxEntries is how large a cache grows before entries are evicted: expired
+// ones first, then whichever come up until it is back under.
+const maxEntries = 10000
+
+// Loader fetches the current value for a key.
+type Loader[V any] func(ctx context.Context, key uuid.UUID) (V, error)
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// inflight counts the loads running for a key. Forget bumps gen so that
+// loads which started before it don't cache what they read.
+type inflight struct {
+	loads int
+	gen   uint64
+}
+
+// Cache remembers what Loader returned for each key for a short while, so
+// checks made on every authenticated request don't each cost a database
+// round trip. Errors are never cached.
+type Cache[V any] struct {
+	ttl  time.Duration
+	load Loader[V]
+
+	mu       sync.Mutex
+	entries  map[uuid.UUID]entry[V]
+	inflight map[uuid.UUID]*inflight
+}
+
+// New returns an empty cache that keeps values for ttl.
+func New[V any](ttl time.Duration, load Loader[V]) *Cache[V] {
+	return &Cache[V]{
+		ttl:      ttl,
+		load:     load,
+		entries:  make(map[uuid.UUID]entry[V]),
+		inflight: make(map[uuid.UUID]*inflight),
+	}
+}
+
+// Get returns the value for key, loading it if it isn't cached or has expired.
+// A value loaded while the key was forgotten is returned but not cached.
+func (c *Cache[V]) Get(ctx context.Context, key uuid.UUID) (V, error) {
+	now := time.Now()
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok && now.Before(e.expiresAt) {
+		c.mu.Unlock()
+		return e.value, nil
+	}
+	f := c.inflight[key]
+	if f == nil {
+		f = &inflight{}
+		c.inflight[key] = f
+	}
+	f.loads++
+	gen := f.gen
+	c.mu.Unlock()
+
+	value, err := c.load(ctx, key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if f.loads--; f.loads == 0 {
+		delete(c.inflight, key)
+	}
+	if err != nil || f.gen != gen {
+		return value, err
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = entry[V]{value: value, expiresAt: now.Add(c.ttl)}
+	return value, nil
+}
+
+// evict makes room for one more entry. c.mu must be held.
+func (c *Cache[V]) evict(now time.Time) {
+	for k, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	for k := range c.entries {
+		if len(c.entries) < maxEntries {
+			break
+		}
+		delete(c.entries, k)
+	}
+}
+
+// Forget drops key so the next Get loads it afresh, and stops loads already
+// under way from caching what they read. Call it after changing what the
+// loader would return; other server instances only notice once their entry
+// expires.
+func (c *Cache[V]) Forget(key uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	if f := c.inflight[key]; f != nil {
+		f.gen++
+	}
+}
@@ -0,0 +1,123 @@
+package chirptext
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultMaxLength is the chirp length limit, in user-perceived characters,
+// used when none is configured.
+const DefaultMaxLength = 140
+
+// Violation codes reported by Validate.
+const (
+	CodeInvalidUTF8      = "invalid_utf8"
+	CodeControlCharacter = "control_character"
+	CodeTooLong          = "too_long"
+	// CodeProfanity is reported by callers that reject blocked words.
+	CodeProfanity = "profanity"
+)
+
+// Violation describes one way a chirp body breaks the rules.
+type Violation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Position is the index, in characters, of the offending character.
+	Position *int `json:"position,omitempty"`
+	// Limit and Length are set for too_long.
+	Limit  int `json:"limit,omitempty"`
+	Length int `json:"length,omitempty"`
+}
+
+// ValidationError carries every violation found in a chirp body.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validator checks chirp bodies against a length limit.
+type Validator struct {
+	MaxLength int
+}
+
+// NewValidator returns a Validator allowing maxLength characters, falling
+// back to DefaultMaxLength when maxLength is not positive.
+func NewValidator(maxLength int) Validator {
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLength
+	}
+	return Validator{MaxLength: maxLength}
+}
+
+// Length counts the user-perceived characters (grapheme clusters) in s after
+// NFC normalization, so "é" is one character however it was typed, and so is
+// a family emoji made of several code points.
+func Length(s string) int {
+	return uniseg.GraphemeClusterCount(norm.NFC.String(s))
+}
+
+// Validate normalizes body to NFC and checks it. It returns the normalized
+// body, or a *ValidationError listing every violation found.
+func (v Validator) Validate(body string) (string, error) {
+	if !utf8.ValidString(body) {
+		return "", &ValidationError{Violations: []Violation{{
+			Code:    CodeInvalidUTF8,
+			Message: "Chirp is not valid UTF-8",
+		}}}
+	}
+	body = norm.NFC.String(body)
+
+	var violations []Violation
+	length := 0
+	graphemes := uniseg.NewGraphemes(body)
+	for graphemes.Next() {
+		for _, r := range graphemes.Runes() {
+			if isDisallowedControl(r) {
+				pos := length
+				violations = append(violations, Violation{
+					Code:     CodeControlCharacter,
+					Message:  fmt.Sprintf("Chirp contains control character %U", r),
+					Position: &pos,
+				})
+				break
+			}
+		}
+		length++
+	}
+
+	if length > v.MaxLength {
+		violations = append(violations, Violation{
+			Code:    CodeTooLong,
+			Message: "Chirp is too long",
+			Limit:   v.MaxLength,
+			Length:  length,
+		})
+	}
+
+	if len(violations) > 0 {
+		return "", &ValidationError{Violations: violations}
+	}
+	return body, nil
+}
+
+// isDisallowedControl reports whether r is a control character other than the
+// line breaks and tabs that can legitimately appear in a chirp.
+func isDisallowedControl(r rune) bool {
+	switch r {
+	case '\n', '\r', '\t':
+		return false
+	}
+	return unicode.IsControl(r)
+}
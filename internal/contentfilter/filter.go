@@ -0,0 +1,113 @@
+package contentfilter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/entities"
+)
+
+// Action decides what a viewer sees in place of a chirp their filters match.
+type Action string
+
+const (
+	// ActionHide leaves matching chirps out entirely.
+	ActionHide Action = "hide"
+	// ActionCollapse keeps matching chirps but marks them as filtered.
+	ActionCollapse Action = "collapse"
+)
+
+const (
+	// MaxEntries caps each of the muted lists.
+	MaxEntries = 200
+	// MaxWordLength caps a muted word or phrase, in runes.
+	MaxWordLength = 50
+)
+
+// ParseAction parses an action name, defaulting to ActionHide for the empty string.
+func ParseAction(s string) (Action, error) {
+	switch Action(s) {
+	case "", ActionHide:
+		return ActionHide, nil
+	case ActionCollapse:
+		return ActionCollapse, nil
+	}
+	return "", fmt.Errorf("unknown filter action %q", s)
+}
+
+// NormalizeWords trims, lowercases and dedupes muted words, rejecting any
+// that are empty, too long or not made of letters and digits alone. Filters
+// match them against the whole words of a chirp, so "spoilers" mutes
+// "SPOILERS!" but not "spoilery".
+func NormalizeWords(words []string) ([]string, error) {
+	if len(words) > MaxEntries {
+		return nil, fmt.Errorf("at most %d muted words are allowed", MaxEntries)
+	}
+	out := []string{}
+	seen := map[string]bool{}
+	for _, w := range words {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w == "" || strings.ContainsFunc(w, notWordRune) {
+			return nil, fmt.Errorf("muted word %q must be a single word of letters and digits", w)
+		}
+		if utf8.RuneCountInString(w) > MaxWordLength {
+			return nil, fmt.Errorf("muted word %q is too long", w)
+		}
+		if !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func notWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// NormalizeHashtags lowercases and dedupes muted hashtags, with or without
+// their leading '#'.
+func NormalizeHashtags(hashtags []string) ([]string, error) {
+	if len(hashtags) > MaxEntries {
+		return nil, fmt.Errorf("at most %d muted hashtags are allowed", MaxEntries)
+	}
+	out := []string{}
+	seen := map[string]bool{}
+	for _, h := range hashtags {
+		tag := strings.TrimPrefix(strings.TrimSpace(h), "#")
+		// A valid tag is exactly what extraction would find in "#tag"
+		found := entities.ExtractHashtags("#" + tag)
+		if len(found) != 1 || found[0] != strings.ToLower(tag) {
+			return nil, fmt.Errorf("muted hashtag %q is not a valid hashtag", h)
+		}
+		if !seen[found[0]] {
+			seen[found[0]] = true
+			out = append(out, found[0])
+		}
+	}
+	return out, nil
+}
+
+// NormalizeUsers parses and dedupes muted user IDs.
+func NormalizeUsers(ids []string) ([]uuid.UUID, error) {
+	if len(ids) > MaxEntries {
+		return nil, fmt.Errorf("at most %d muted users are allowed", MaxEntries)
+	}
+	out := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+	for _, s := range ids {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("muted user %q is not a valid user ID", s)
+		}
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
@@ -7,29 +7,56 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countChirpsByUser = `-- name: CountChirpsByUser :one
+SELECT COUNT(*)
+FROM chirps
+WHERE user_id = $1
+`
+
+func (q *Queries) CountChirpsByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChirpsByUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_id, original_id, repost_kind)
 VALUES (
     gen_random_uuid(),  -- id
     NOW(),              -- created_at
     NOW(),              -- updated_at
     $1,                 -- body
-    $2                  -- user_id
+    $2,                 -- user_id
+    $3,                 -- parent_id
+    $4,                 -- original_id
+    $5                  -- repost_kind
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, search_vector, parent_id, like_count, original_id, repost_kind, hidden_at
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+	Body       string
+	UserID     uuid.UUID
+	ParentID   uuid.NullUUID
+	OriginalID uuid.NullUUID
+	RepostKind sql.NullString
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.ParentID,
+		arg.OriginalID,
+		arg.RepostKind,
+	)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,6 +64,12 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.ParentID,
+		&i.LikeCount,
+		&i.OriginalID,
+		&i.RepostKind,
+		&i.HiddenAt,
 	)
 	return i, err
 }
@@ -70,7 +103,7 @@ func (q *Queries) DeleteChirp(ctx context.Context, arg DeleteChirpParams) (int64
 }
 
 const getChirpByID = `-- name: GetChirpByID :one
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, like_count, original_id, repost_kind, hidden_at
 FROM chirps
 WHERE id = $1
 `
@@ -84,12 +117,44 @@ func (q *Queries) GetChirpByID(ctx context.Context, id uuid.UUID) (Chirp, error)
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.ParentID,
+		&i.LikeCount,
+		&i.OriginalID,
+		&i.RepostKind,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const getChirpByIDForUpdate = `-- name: GetChirpByIDForUpdate :one
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, like_count, original_id, repost_kind, hidden_at
+FROM chirps
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetChirpByIDForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpByIDForUpdate, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.ParentID,
+		&i.LikeCount,
+		&i.OriginalID,
+		&i.RepostKind,
+		&i.HiddenAt,
 	)
 	return i, err
 }
 
 const listChirps = `-- name: ListChirps :many
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, like_count, original_id, repost_kind, hidden_at
 FROM chirps
 ORDER BY created_at ASC
 `
@@ -109,6 +174,53 @@ func (q *Queries) ListChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.SearchVector,
+			&i.ParentID,
+			&i.LikeCount,
+			&i.OriginalID,
+			&i.RepostKind,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsByIDs = `-- name: ListChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, like_count, original_id, repost_kind, hidden_at
+FROM chirps
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) ListChirpsByIDs(ctx context.Context, ids []uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentID,
+			&i.LikeCount,
+			&i.OriginalID,
+			&i.RepostKind,
+			&i.HiddenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -124,7 +236,7 @@ func (q *Queries) ListChirps(ctx context.Context) ([]Chirp, error) {
 }
 
 const listChirpsByUser = `-- name: ListChirpsByUser :many
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, like_count, original_id, repost_kind, hidden_at
 FROM chirps
 WHERE user_id = $1
 ORDER BY created_at DESC
@@ -145,6 +257,12 @@ func (q *Queries) ListChirpsByUser(ctx context.Context, userID uuid.UUID) ([]Chi
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.SearchVector,
+			&i.ParentID,
+			&i.LikeCount,
+			&i.OriginalID,
+			&i.RepostKind,
+			&i.HiddenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -158,3 +276,317 @@ func (q *Queries) ListChirpsByUser(ctx context.Context, userID uuid.UUID) ([]Chi
 	}
 	return items, nil
 }
+
+const listChirpsByUserPage = `-- name: ListChirpsByUserPage :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, like_count, original_id, repost_kind, hidden_at
+FROM chirps
+WHERE user_id = $1
+  AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) < ($2::timestamp, $3::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListChirpsByUserPageParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+// Every chirp the user wrote, hidden ones included, newest first.
+func (q *Queries) ListChirpsByUserPage(ctx context.Context, arg ListChirpsByUserPageParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByUserPage,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentID,
+			&i.LikeCount,
+			&i.OriginalID,
+			&i.RepostKind,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsPageAsc = `-- name: ListChirpsPageAsc :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, like_count, original_id, repost_kind, hidden_at
+FROM chirps
+WHERE ($1::uuid IS NULL OR user_id = $1::uuid)
+  AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) > ($2::timestamp, $3::uuid)
+  )
+  AND hidden_at IS NULL
+  AND user_id NOT IN (SELECT id FROM inactive_users)
+  AND NOT hidden_by_relationship($4::uuid, chirps.user_id)
+  -- Leave out chirps the viewer's filters hide
+  AND chirp_filter_action($4::uuid, chirps.id) IS DISTINCT FROM 'hide'
+ORDER BY created_at ASC, id ASC
+LIMIT $5
+`
+
+type ListChirpsPageAscParams struct {
+	AuthorID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	ViewerID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) ListChirpsPageAsc(ctx context.Context, arg ListChirpsPageAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsPageAsc,
+		arg.AuthorID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.ViewerID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentID,
+			&i.LikeCount,
+			&i.OriginalID,
+			&i.RepostKind,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsPageDesc = `-- name: ListChirpsPageDesc :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, like_count, original_id, repost_kind, hidden_at
+FROM chirps
+WHERE ($1::uuid IS NULL OR user_id = $1::uuid)
+  AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) < ($2::timestamp, $3::uuid)
+  )
+  AND hidden_at IS NULL
+  AND user_id NOT IN (SELECT id FROM inactive_users)
+  AND NOT hidden_by_relationship($4::uuid, chirps.user_id)
+  -- Leave out chirps the viewer's filters hide
+  AND chirp_filter_action($4::uuid, chirps.id) IS DISTINCT FROM 'hide'
+ORDER BY created_at DESC, id DESC
+LIMIT $5
+`
+
+type ListChirpsPageDescParams struct {
+	AuthorID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	ViewerID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) ListChirpsPageDesc(ctx context.Context, arg ListChirpsPageDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsPageDesc,
+		arg.AuthorID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.ViewerID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentID,
+			&i.LikeCount,
+			&i.OriginalID,
+			&i.RepostKind,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT
+    chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_id, chirps.like_count, chirps.original_id, chirps.repost_kind, chirps.hidden_at,
+    ts_rank_cd(search_vector, to_tsquery('english', $1))::real AS rank,
+    -- The body is HTML-escaped before highlighting so the only markup in the
+    -- snippet is the <mark> tags added here.
+    ts_headline(
+        'english',
+        replace(replace(replace(replace(replace(body,
+            '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'),
+        to_tsquery('english', $1),
+        'StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=5'
+    )::text AS snippet
+FROM chirps
+WHERE search_vector @@ to_tsquery('english', $1)
+  AND ($2::uuid IS NULL OR user_id = $2::uuid)
+  AND ($3::timestamp IS NULL OR created_at >= $3::timestamp)
+  AND ($4::timestamp IS NULL OR created_at < $4::timestamp)
+  AND hidden_at IS NULL
+  AND user_id NOT IN (SELECT id FROM inactive_users)
+  AND NOT hidden_by_relationship($5::uuid, chirps.user_id)
+  -- Leave out chirps the viewer's filters hide
+  AND chirp_filter_action($5::uuid, id) IS DISTINCT FROM 'hide'
+ORDER BY rank DESC, created_at DESC, id DESC
+LIMIT $6
+OFFSET $7
+`
+
+type SearchChirpsParams struct {
+	Query    string
+	AuthorID uuid.NullUUID
+	Since    sql.NullTime
+	Until    sql.NullTime
+	ViewerID uuid.NullUUID
+	Limit    int32
+	Offset   int32
+}
+
+type SearchChirpsRow struct {
+	Chirp   Chirp
+	Rank    float32
+	Snippet string
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps,
+		arg.Query,
+		arg.AuthorID,
+		arg.Since,
+		arg.Until,
+		arg.ViewerID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.ParentID,
+			&i.Chirp.LikeCount,
+			&i.Chirp.OriginalID,
+			&i.Chirp.RepostKind,
+			&i.Chirp.HiddenAt,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps
+SET
+    body = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, search_vector, parent_id, like_count, original_id, repost_kind, hidden_at
+`
+
+type UpdateChirpBodyParams struct {
+	ID   uuid.UUID
+	Body string
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.ID, arg.Body)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.ParentID,
+		&i.LikeCount,
+		&i.OriginalID,
+		&i.RepostKind,
+		&i.HiddenAt,
+	)
+	return i, err
+}
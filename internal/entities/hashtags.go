@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxHashtagLength is the longest tag, in runes, that is indexed.
+const MaxHashtagLength = 50
+
+// ExtractHashtags returns the distinct #hashtags in body, lowercased, in the
+// order they first appear. A tag is a run of letters, digits and underscores
+// right after a '#' that is not itself glued to a preceding word, and it must
+// contain at least one letter so "#1" is not a tag.
+func ExtractHashtags(body string) []string {
+	return extract(body, '#')
+}
+
+// extract finds the distinct tokens introduced by marker in body.
+func extract(body string, marker rune) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	runes := []rune(body)
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != marker {
+			continue
+		}
+		if i > 0 && isTagRune(runes[i-1]) {
+			continue
+		}
+
+		j := i + 1
+		hasLetter := false
+		for j < len(runes) && isTagRune(runes[j]) {
+			if unicode.IsLetter(runes[j]) {
+				hasLetter = true
+			}
+			j++
+		}
+
+		tag := strings.ToLower(string(runes[i+1 : j]))
+		i = j - 1
+		if !hasLetter || len([]rune(tag)) > MaxHashtagLength || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+func isTagRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,8 @@
+package entities
+
+// ExtractMentions returns the distinct @usernames in body, lowercased, in the
+// order they first appear. Mentions follow the same rules as hashtags, so an
+// email address like "me@example.com" does not mention anyone.
+func ExtractMentions(body string) []string {
+	return extract(body, '@')
+}
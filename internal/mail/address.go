@@ -0,0 +1,34 @@
+package mail
+
+import (
+	"errors"
+	netmail "net/mail"
+	"strings"
+)
+
+// MaxAddressLength is the longest address that fits in an SMTP path.
+const MaxAddressLength = 254
+
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// ValidateAddress checks that s is a bare address such as alice@example.com
+// and returns it without surrounding whitespace. Display names, quoted local
+// parts and domains without a dot are refused; they parse, but nobody
+// signing up means them.
+func ValidateAddress(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || len(s) > MaxAddressLength {
+		return "", ErrInvalidAddress
+	}
+	addr, err := netmail.ParseAddress(s)
+	if err != nil || addr.Name != "" || addr.Address != s {
+		return "", ErrInvalidAddress
+	}
+
+	domain := s[strings.LastIndexByte(s, '@')+1:]
+	if strings.HasPrefix(domain, "[") || !strings.Contains(domain, ".") ||
+		strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") || strings.Contains(domain, "..") {
+		return "", ErrInvalidAddress
+	}
+	return s, nil
+}
@@ -0,0 +1,105 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Reason is the category a reporter picks for a chirp.
+type Reason string
+
+const (
+	ReasonSpam           Reason = "spam"
+	ReasonHarassment     Reason = "harassment"
+	ReasonHate           Reason = "hate"
+	ReasonViolence       Reason = "violence"
+	ReasonSexual         Reason = "sexual"
+	ReasonMisinformation Reason = "misinformation"
+	ReasonOther          Reason = "other"
+)
+
+// Reasons lists every reason in the order clients should offer them.
+var Reasons = []Reason{
+	ReasonSpam,
+	ReasonHarassment,
+	ReasonHate,
+	ReasonViolence,
+	ReasonSexual,
+	ReasonMisinformation,
+	ReasonOther,
+}
+
+// Action is how a moderator resolves the open reports on a chirp.
+type Action string
+
+const (
+	// ActionDismiss closes the reports and leaves the chirp alone.
+	ActionDismiss Action = "dismiss"
+	// ActionHide takes the chirp out of every read.
+	ActionHide Action = "hide"
+	// ActionSuspend hides the chirp and suspends its author.
+	ActionSuspend Action = "suspend"
+	// ActionUnsuspend lifts a suspension. It is logged but never resolves
+	// reports.
+	ActionUnsuspend Action = "unsuspend"
+	// ActionSetRole changes a user's role, with the new role as the note.
+	// Like ActionUnsuspend, it never resolves reports.
+	ActionSetRole Action = "set_role"
+)
+
+const (
+	// MaxDetailsLength caps the free text on a report, in runes.
+	MaxDetailsLength = 500
+	// MaxNoteLength caps a moderator's note on an action, in runes.
+	MaxNoteLength = 1000
+)
+
+var (
+	ErrDetailsLength = fmt.Errorf("details must be at most %d characters", MaxDetailsLength)
+	ErrNoteLength    = fmt.Errorf("note must be at most %d characters", MaxNoteLength)
+	ErrOtherDetails  = errors.New("details are required when the reason is other")
+)
+
+// ParseReason parses a report reason, ignoring case.
+func ParseReason(s string) (Reason, error) {
+	r := Reason(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Reasons {
+		if r == known {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("unknown report reason %q", s)
+}
+
+// ParseAction parses a moderation action.
+func ParseAction(s string) (Action, error) {
+	switch a := Action(s); a {
+	case ActionDismiss, ActionHide, ActionSuspend:
+		return a, nil
+	}
+	return "", fmt.Errorf("unknown moderation action %q", s)
+}
+
+// ValidateDetails trims a report's free text and checks its length. Reports
+// filed as ReasonOther must say what is wrong.
+func ValidateDetails(reason Reason, details string) (string, error) {
+	details = strings.TrimSpace(details)
+	if utf8.RuneCountInString(details) > MaxDetailsLength {
+		return "", ErrDetailsLength
+	}
+	if reason == ReasonOther && details == "" {
+		return "", ErrOtherDetails
+	}
+	return details, nil
+}
+
+// ValidateNote trims a moderator's note and checks its length.
+func ValidateNote(note string) (string, error) {
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > MaxNoteLength {
+		return "", ErrNoteLength
+	}
+	return note, nil
+}
@@ -0,0 +1,65 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor marks a position in a keyset-paginated list ordered by (created_at, id).
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// EncodeCursor turns a cursor into an opaque, URL-safe string.
+func EncodeCursor(c Cursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a string produced by EncodeCursor.
+func DecodeCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, errors.New("malformed cursor")
+	}
+
+	// Expected: "<created_at>|<id>"
+	createdAtStr, idStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return Cursor{}, errors.New("malformed cursor")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return Cursor{}, errors.New("malformed cursor timestamp")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return Cursor{}, errors.New("malformed cursor id")
+	}
+
+	return Cursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+// ParseLimit reads a page size from a query string value, falling back to
+// defaultLimit when it is empty and capping it at maxLimit.
+func ParseLimit(s string, defaultLimit, maxLimit int) (int, error) {
+	if s == "" {
+		return defaultLimit, nil
+	}
+	limit, err := strconv.Atoi(s)
+	if err != nil || limit < 1 {
+		return 0, errors.New("limit must be a positive integer")
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return limit, nil
+}
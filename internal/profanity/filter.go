@@ -0,0 +1,295 @@
+package profanity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Mode decides what happens to a chirp that contains a listed word.
+type Mode string
+
+const (
+	// ModeMask replaces each listed word with MaskText.
+	ModeMask Mode = "mask"
+	// ModeReject refuses the chirp.
+	ModeReject Mode = "reject"
+	// ModeFlag keeps the chirp as written and flags it for review.
+	ModeFlag Mode = "flag"
+)
+
+// ParseMode parses a mode name, defaulting to ModeMask for the empty string.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(strings.ToLower(s)) {
+	case "", ModeMask:
+		return ModeMask, nil
+	case ModeReject:
+		return ModeReject, nil
+	case ModeFlag:
+		return ModeFlag, nil
+	}
+	return "", fmt.Errorf("unknown profanity mode %q", s)
+}
+
+// MaskText replaces each listed word when masking.
+const MaskText = "****"
+
+// Match is one listed word found in a text. Start and End are byte offsets
+// into the text that was searched.
+type Match struct {
+	Word  string
+	Start int
+	End   int
+}
+
+// Filter finds listed words in text. It is safe for concurrent use, and its
+// list can be swapped while it is in use.
+type Filter struct {
+	mu sync.RWMutex
+	// words maps the normalized form of each listed word to the words with it.
+	words map[string][]listed
+}
+
+type listed struct {
+	word string
+	// length is the word's length, in runes, before repeats are collapsed.
+	// A candidate must be at least this long, so repeats can stretch a word
+	// but "as" never matches "ass".
+	length int
+}
+
+// NewFilter returns a filter for words.
+func NewFilter(words []string) *Filter {
+	f := &Filter{}
+	f.SetWords(words)
+	return f
+}
+
+// SetWords replaces the list of words.
+func (f *Filter) SetWords(words []string) {
+	normalized := make(map[string][]listed, len(words))
+	for _, w := range words {
+		folded := fold(w)
+		if folded == "" {
+			continue
+		}
+		key := squeeze(folded)
+		normalized[key] = append(normalized[key], listed{
+			word:   strings.ToLower(strings.TrimSpace(w)),
+			length: utf8.RuneCountInString(folded),
+		})
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.words = normalized
+}
+
+// Words returns the current list, sorted.
+func (f *Filter) Words() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	words := make([]string, 0, len(f.words))
+	for _, ws := range f.words {
+		for _, w := range ws {
+			words = append(words, w.word)
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// Find returns the listed words in text, in order. Matching ignores case,
+// surrounding and embedded punctuation ("kerfuffle!", "k.e.r.f.u.f.f.l.e"),
+// common leetspeak ("k3rfuffl3") and repeated letters ("kerfuffffle"), but
+// only whole words match, so a listed word inside a longer one is left alone.
+func (f *Filter) Find(text string) []Match {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if len(f.words) == 0 {
+		return nil
+	}
+
+	var matches []Match
+	for _, tok := range spans(text, unicode.IsSpace) {
+		if w, m, ok := f.lookupSpan(text, tok); ok {
+			matches = append(matches, Match{Word: w, Start: m.start, End: m.end})
+			continue
+		}
+		// Punctuation can also glue several words together: "fornax,kerfuffle"
+		for _, part := range spans(text[tok.start:tok.end], isSeparator) {
+			part = span{tok.start + part.start, tok.start + part.end}
+			if w, m, ok := f.lookupSpan(text, part); ok {
+				matches = append(matches, Match{Word: w, Start: m.start, End: m.end})
+			}
+		}
+	}
+	return matches
+}
+
+// lookupSpan looks up the word in sp, trying it with and without the
+// leetspeak characters at either edge, so "$hit" reads as "shit" while the
+// "!" in "kerfuffle!" is still dropped as punctuation. It returns the
+// listed word and the part of sp that matched. The caller holds f.mu.
+func (f *Filter) lookupSpan(s string, sp span) (string, span, bool) {
+	wide, narrow := trim(s, sp, isWordOrLeet), trim(s, sp, isWordRune)
+	starts, ends := []int{wide.start}, []int{wide.end}
+	if narrow.start != wide.start {
+		starts = append(starts, narrow.start)
+	}
+	if narrow.end != wide.end {
+		ends = append(ends, narrow.end)
+	}
+	for _, start := range starts {
+		for _, end := range ends {
+			if start >= end {
+				continue
+			}
+			if w, ok := f.lookup(s[start:end]); ok {
+				return w, span{start, end}, true
+			}
+		}
+	}
+	return "", span{}, false
+}
+
+// lookup returns the listed word candidate matches. The caller holds f.mu.
+func (f *Filter) lookup(candidate string) (string, bool) {
+	folded := fold(candidate)
+	length := utf8.RuneCountInString(folded)
+	for _, w := range f.words[squeeze(folded)] {
+		if length >= w.length {
+			return w.word, true
+		}
+	}
+	return "", false
+}
+
+// Mask replaces each match in text with MaskText, leaving everything else,
+// whitespace included, exactly as it was.
+func Mask(text string, matches []Match) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(text[last:m.Start])
+		b.WriteString(MaskText)
+		last = m.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// leet maps characters commonly swapped in for letters.
+var leet = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'8': 'b',
+	'@': 'a',
+	'$': 's',
+	'!': 'i',
+	'|': 'l',
+	'+': 't',
+}
+
+// Normalize reduces a word to the form words are compared in: lowercase,
+// leetspeak undone, other punctuation dropped and runs of the same letter
+// collapsed to one. A word that normalizes to "" can never match.
+func Normalize(word string) string {
+	return squeeze(fold(word))
+}
+
+// fold lowercases word, undoes leetspeak and drops other punctuation.
+func fold(word string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(word) {
+		if l, ok := leet[r]; ok {
+			r = l
+		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// squeeze collapses runs of the same rune to one.
+func squeeze(s string) string {
+	var b strings.Builder
+	var prev rune
+	for _, r := range s {
+		if r != prev {
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
+
+type span struct {
+	start, end int
+}
+
+// spans splits s into the byte ranges between runs of separators.
+func spans(s string, sep func(rune) bool) []span {
+	var out []span
+	start := -1
+	for i, r := range s {
+		if sep(r) {
+			if start >= 0 {
+				out = append(out, span{start, i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		out = append(out, span{start, len(s)})
+	}
+	return out
+}
+
+// trim narrows sp so it starts and ends on a rune keep accepts, dropping
+// punctuation such as the "!" in "kerfuffle!".
+func trim(s string, sp span, keep func(rune) bool) span {
+	for sp.start < sp.end {
+		r, size := utf8.DecodeRuneInString(s[sp.start:sp.end])
+		if keep(r) {
+			break
+		}
+		sp.start += size
+	}
+	for sp.end > sp.start {
+		r, size := utf8.DecodeLastRuneInString(s[sp.start:sp.end])
+		if keep(r) {
+			break
+		}
+		sp.end -= size
+	}
+	return sp
+}
+
+// isWordRune reports whether r is a letter or digit.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isWordOrLeet reports whether r is a letter, a digit or a character used
+// as leetspeak.
+func isWordOrLeet(r rune) bool {
+	_, ok := leet[r]
+	return ok || isWordRune(r)
+}
+
+// isSeparator reports whether r splits a token into words. Characters used
+// as leetspeak never do.
+func isSeparator(r rune) bool {
+	return !isWordOrLeet(r)
+}
@@ -0,0 +1,106 @@
+package profanity
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Store is where the word list lives between restarts.
+type Store interface {
+	Load(ctx context.Context) ([]string, error)
+	// Add stores word, reporting false if it was already listed.
+	Add(ctx context.Context, word string) (bool, error)
+	// Remove deletes word, reporting false if it was not listed.
+	Remove(ctx context.Context, word string) (bool, error)
+}
+
+// FileStore keeps the list in a text file with one word per line. Blank
+// lines and lines starting with '#' are ignored.
+type FileStore struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (s *FileStore) Load(ctx context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+func (s *FileStore) Add(ctx context.Context, word string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	words, err := s.read()
+	if err != nil {
+		return false, err
+	}
+	for _, w := range words {
+		if w == word {
+			return false, nil
+		}
+	}
+	return true, s.write(append(words, word))
+}
+
+func (s *FileStore) Remove(ctx context.Context, word string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	words, err := s.read()
+	if err != nil {
+		return false, err
+	}
+	kept := words[:0]
+	for _, w := range words {
+		if w != word {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) == len(words) {
+		return false, nil
+	}
+	return true, s.write(kept)
+}
+
+func (s *FileStore) read() ([]string, error) {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	words := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words, scanner.Err()
+}
+
+// write replaces the file in one rename so a crash never leaves half a list.
+func (s *FileStore) write(words []string) error {
+	sort.Strings(words)
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), ".profanity-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(strings.Join(words, "\n") + "\n"); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
@@ -0,0 +1,99 @@
+package profile
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MinUsernameLength    = 3
+	MaxUsernameLength    = 30
+	MaxDisplayNameLength = 50
+	MaxBioLength         = 160
+	MaxAvatarURLLength   = 2048
+)
+
+var (
+	ErrUsernameLength   = errors.New("username must be between 3 and 30 characters")
+	ErrUsernameChars    = errors.New("username may only contain letters, digits and underscores")
+	ErrUsernameLetter   = errors.New("username must contain at least one letter")
+	ErrUsernameReserved = errors.New("username is reserved")
+	ErrDisplayName      = errors.New("display name is too long")
+	ErrBio              = errors.New("bio is too long")
+	ErrAvatarURL        = errors.New("avatar url must be an absolute http or https url")
+	ErrAvatarURLLength  = errors.New("avatar url is too long")
+)
+
+// reserved holds names that would collide with routes or impersonate staff.
+var reserved = map[string]bool{
+	"me":      true,
+	"admin":   true,
+	"api":     true,
+	"chirpy":  true,
+	"support": true,
+}
+
+// NormalizeUsername returns the form usernames are compared in. Usernames keep
+// the case they were registered with but are unique regardless of it.
+func NormalizeUsername(username string) string {
+	return strings.ToLower(username)
+}
+
+// ValidateUsername checks that username can be registered. Usernames are
+// plain ASCII so that every valid one can be @mentioned.
+func ValidateUsername(username string) error {
+	if len(username) < MinUsernameLength || len(username) > MaxUsernameLength {
+		return ErrUsernameLength
+	}
+	hasLetter := false
+	for _, r := range username {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			hasLetter = true
+		case r >= '0' && r <= '9', r == '_':
+		default:
+			return ErrUsernameChars
+		}
+	}
+	if !hasLetter {
+		return ErrUsernameLetter
+	}
+	if reserved[NormalizeUsername(username)] {
+		return ErrUsernameReserved
+	}
+	return nil
+}
+
+// ValidateDisplayName checks the free-form name shown next to the username.
+func ValidateDisplayName(name string) error {
+	if utf8.RuneCountInString(name) > MaxDisplayNameLength {
+		return ErrDisplayName
+	}
+	return nil
+}
+
+// ValidateBio checks the short profile description.
+func ValidateBio(bio string) error {
+	if utf8.RuneCountInString(bio) > MaxBioLength {
+		return ErrBio
+	}
+	return nil
+}
+
+// ValidateAvatarURL accepts an empty string, which clears the avatar, or an
+// absolute http(s) URL.
+func ValidateAvatarURL(avatarURL string) error {
+	if avatarURL == "" {
+		return nil
+	}
+	if len(avatarURL) > MaxAvatarURLLength {
+		return ErrAvatarURLLength
+	}
+	u, err := url.Parse(avatarURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrAvatarURL
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// BuildTSQuery converts a user-facing search string into Postgres to_tsquery syntax.
+//
+// Supported syntax:
+//   - words are ANDed together:            go chirpy      -> go & chirpy
+//   - "quoted text" is a phrase query:     "hello world"  -> (hello <-> world)
+//   - a trailing * is a prefix query:      chirp*         -> chirp:*
+//   - a leading - excludes a word/phrase:  -spam          -> !spam
+//   - OR between two terms:                go OR rust     -> go | rust
+//
+// OR binds tighter than the implicit AND, so "go OR rust -spam" becomes
+// (go | rust) & !spam rather than letting Postgres' precedence pick.
+//
+// Everything that is not a letter or digit is stripped from words, so the
+// result can never contain tsquery syntax the user did not ask for.
+func BuildTSQuery(q string) (string, error) {
+	// groups are ANDed together; the terms within a group are ORed.
+	var groups [][]string
+	pendingOr := false
+
+	appendTerm := func(term string) {
+		if pendingOr {
+			last := len(groups) - 1
+			groups[last] = append(groups[last], term)
+		} else {
+			groups = append(groups, []string{term})
+		}
+		pendingOr = false
+	}
+
+	rest := strings.TrimSpace(q)
+	for rest != "" {
+		negate := false
+		if rest[0] == '-' {
+			negate = true
+			rest = rest[1:]
+		}
+
+		var term string
+		if strings.HasPrefix(rest, `"`) {
+			// Phrase: everything up to the closing quote (or the end of input)
+			phrase, after, _ := strings.Cut(rest[1:], `"`)
+			rest = after
+			words := []string{}
+			for _, w := range strings.Fields(phrase) {
+				if lexeme := cleanWord(w); lexeme != "" {
+					words = append(words, lexeme)
+				}
+			}
+			switch len(words) {
+			case 0:
+			case 1:
+				term = words[0]
+			default:
+				term = "(" + strings.Join(words, " <-> ") + ")"
+			}
+		} else {
+			word, after := rest, ""
+			if i := strings.IndexFunc(rest, unicode.IsSpace); i >= 0 {
+				word, after = rest[:i], rest[i:]
+			}
+			rest = after
+			if word == "OR" && !negate {
+				pendingOr = len(groups) > 0
+				rest = strings.TrimSpace(rest)
+				continue
+			}
+			if lexeme := cleanWord(word); lexeme != "" {
+				term = lexeme
+				if strings.HasSuffix(word, "*") {
+					term += ":*"
+				}
+			}
+		}
+
+		if term != "" {
+			if negate {
+				term = "!" + term
+			}
+			appendTerm(term)
+		}
+		rest = strings.TrimSpace(rest)
+	}
+
+	if len(groups) == 0 {
+		return "", errors.New("search query has no searchable words")
+	}
+	parts := make([]string, len(groups))
+	for i, group := range groups {
+		parts[i] = strings.Join(group, " | ")
+		if len(group) > 1 && len(groups) > 1 {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " & "), nil
+}
+
+// cleanWord lowercases a word and drops every rune that is not a letter or digit.
+func cleanWord(w string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, w)
+}
@@ -0,0 +1,200 @@
+package auth_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/account"
+)
+
+// account.
+func TestAccountCheck(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	never := sql.NullTime{}
+	later := sql.NullTime{Time: now.Add(time.Hour), Valid: true}
+	earlier := sql.NullTime{Time: now.Add(-time.Hour), Valid: true}
+
+	cases := []struct {
+		status string
+		until  sql.NullTime
+		want   error
+	}{
+		{"active", never, nil},
+		{"deactivated", never, account.ErrDeactivated},
+		{"deleted", never, account.ErrDeleted},
+		{"suspended", never, account.ErrSuspended},
+		{"suspended", later, account.ErrSuspended},
+		{"suspended", earlier, nil},
+	}
+	for _, c := range cases {
+		if got := account.Check(c.status, "spam", c.until, now); !errors.Is(got, c.want) {
+			t.Fatalf("for %s until %v expected %v got %v", c.status, c.until, c.want, got)
+		}
+	}
+
+	if err := account.Check("banned", "", never, now); err == nil {
+		t.Fatalf("expected an error for an unknown status")
+	}
+}
+
+func TestSuspendedErrorMessage(t *testing.T) {
+	until := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	err := account.Check("suspended", "spam", sql.NullTime{Time: until, Valid: true}, until.Add(-time.Minute))
+	if msg := err.Error(); !strings.Contains(msg, "2024-05-02T00:00:00Z") || !strings.HasSuffix(msg, ": spam") {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
+
+func TestExportAccount(t *testing.T) {
+	s := startServer(t)
+	u, other := s.newUser(t), s.newUser(t)
+	mine := s.postChirp(t, u, "mine to export")
+	s.expect(t, http.StatusOK, "PUT", "/api/chirps/"+mine.ID, u.Token, map[string]string{"body": "mine to export, edited"}, nil)
+	hidden := s.postChirp(t, u, "hidden but still mine")
+	if _, err := s.db.Exec("UPDATE chirps SET hidden_at = NOW() WHERE id = $1", hidden.ID); err != nil {
+		t.Fatalf("couldn't hide chirp: %v", err)
+	}
+	theirs := s.postChirp(t, other, "liked and reported")
+
+	s.expect(t, http.StatusOK, "POST", "/api/chirps/"+theirs.ID+"/like", u.Token, nil, nil)
+	s.expect(t, http.StatusNoContent, "POST", "/api/users/"+other.ID+"/follow", u.Token, nil, nil)
+	s.expect(t, http.StatusNoContent, "POST", "/api/users/"+u.ID+"/follow", other.Token, nil, nil)
+	s.expect(t, http.StatusNoContent, "POST", "/api/users/"+other.ID+"/mute", u.Token, nil, nil)
+	s.expect(t, http.StatusCreated, "POST", "/api/chirps/"+theirs.ID+"/report", u.Token, map[string]string{"reason": "spam"}, nil)
+	s.expect(t, http.StatusOK, "PUT", "/api/users/me/filters", u.Token, map[string]any{"muted_words": []string{"export"}}, nil)
+
+	type related struct {
+		UserID string `json:"user_id"`
+	}
+	var export struct {
+		Profile struct {
+			ID string `json:"id"`
+		} `json:"profile"`
+		Sessions []struct{} `json:"sessions"`
+		Filters  struct {
+			MutedWords []string `json:"muted_words"`
+		} `json:"filters"`
+		Chirps    []testChirp `json:"chirps"`
+		Revisions []struct {
+			ChirpID string `json:"chirp_id"`
+			Body    string `json:"body"`
+		} `json:"revisions"`
+		Likes []struct {
+			ChirpID string `json:"chirp_id"`
+		} `json:"likes"`
+		Following     []related `json:"following"`
+		Followers     []related `json:"followers"`
+		Blocks        []related `json:"blocks"`
+		Mutes         []related `json:"mutes"`
+		Notifications []struct {
+			Kind    string `json:"kind"`
+			ActorID string `json:"actor_id"`
+		} `json:"notifications"`
+		Reports []struct {
+			ChirpID string `json:"chirp_id"`
+		} `json:"reports"`
+		Complete bool `json:"complete"`
+	}
+	s.expect(t, http.StatusOK, "GET", "/api/users/me/export", u.Token, nil, &export)
+
+	if export.Profile.ID != u.ID || len(export.Sessions) != 1 {
+		t.Fatalf("expected the profile and one session, got %+v", export)
+	}
+	if len(export.Filters.MutedWords) != 1 || export.Filters.MutedWords[0] != "export" {
+		t.Fatalf("expected the saved filters, got %+v", export.Filters)
+	}
+	if ids := chirpIDs(export.Chirps); len(ids) != 2 || ids[0] != hidden.ID || ids[1] != mine.ID {
+		t.Fatalf("expected both chirps newest first, hidden one included, got %v", ids)
+	}
+	if len(export.Revisions) != 1 || export.Revisions[0].ChirpID != mine.ID || export.Revisions[0].Body != "mine to export" {
+		t.Fatalf("expected the chirp's earlier body, got %+v", export.Revisions)
+	}
+	if len(export.Likes) != 1 || export.Likes[0].ChirpID != theirs.ID {
+		t.Fatalf("expected the like, got %+v", export.Likes)
+	}
+	if len(export.Following) != 1 || export.Following[0].UserID != other.ID ||
+		len(export.Followers) != 1 || export.Followers[0].UserID != other.ID {
+		t.Fatalf("expected follows both ways, got %+v and %+v", export.Following, export.Followers)
+	}
+	if len(export.Blocks) != 0 || len(export.Mutes) != 1 || export.Mutes[0].UserID != other.ID {
+		t.Fatalf("expected just the mute, got %+v and %+v", export.Blocks, export.Mutes)
+	}
+	// The notification is there even though its actor is muted
+	if len(export.Notifications) != 1 || export.Notifications[0].Kind != "follow" || export.Notifications[0].ActorID != other.ID {
+		t.Fatalf("expected the follow notification, got %+v", export.Notifications)
+	}
+	if len(export.Reports) != 1 || export.Reports[0].ChirpID != theirs.ID {
+		t.Fatalf("expected the report, got %+v", export.Reports)
+	}
+	if !export.Complete {
+		t.Fatalf("expected the export to be marked complete")
+	}
+}
+
+func TestPurgeRemovesDependentRows(t *testing.T) {
+	s := startServer(t)
+	u, other := s.newUser(t), s.newUser(t)
+	mine := s.postChirp(t, u, "gone with its author")
+	theirs := s.postChirp(t, other, "liked by someone who leaves")
+
+	s.expect(t, http.StatusOK, "POST", "/api/chirps/"+mine.ID+"/like", other.Token, nil, nil)
+	s.expect(t, http.StatusOK, "POST", "/api/chirps/"+theirs.ID+"/like", u.Token, nil, nil)
+	s.expect(t, http.StatusNoContent, "POST", "/api/users/"+other.ID+"/follow", u.Token, nil, nil)
+	s.expect(t, http.StatusNoContent, "POST", "/api/users/"+u.ID+"/follow", other.Token, nil, nil)
+	s.expect(t, http.StatusCreated, "POST", "/api/chirps/"+theirs.ID+"/report", u.Token, map[string]string{"reason": "spam"}, nil)
+	s.expect(t, http.StatusOK, "PUT", "/api/users/me/filters", u.Token, map[string]any{"muted_words": []string{"purge"}}, nil)
+
+	// A moderator named in the moderation log is erased all the same
+	mod := s.withRole(t, s.newUser(t), "moderator")
+	suspended := s.newUser(t)
+	s.expect(t, http.StatusOK, "POST", "/admin/users/"+suspended.ID+"/suspend", mod.Token, map[string]string{"reason": "testing"}, nil)
+
+	for _, id := range []string{u.ID, mod.ID} {
+		if _, err := s.db.Exec("UPDATE users SET status = 'deleted', delete_after = $2 WHERE id = $1", id, time.Now().UTC().Add(-time.Minute)); err != nil {
+			t.Fatalf("couldn't schedule deletion: %v", err)
+		}
+	}
+	if _, err := s.q.DeleteUsersPastGracePeriod(context.Background(), sql.NullTime{Time: time.Now().UTC(), Valid: true}); err != nil {
+		t.Fatalf("couldn't purge: %v", err)
+	}
+
+	leftovers := map[string]string{
+		"user":          "SELECT COUNT(*) FROM users WHERE id = $1",
+		"chirps":        "SELECT COUNT(*) FROM chirps WHERE user_id = $1",
+		"likes":         "SELECT COUNT(*) FROM chirp_likes WHERE user_id = $1",
+		"follows":       "SELECT COUNT(*) FROM follows WHERE follower_id = $1 OR followee_id = $1",
+		"notifications": "SELECT COUNT(*) FROM notifications WHERE user_id = $1 OR actor_id = $1",
+		"reports":       "SELECT COUNT(*) FROM chirp_reports WHERE reporter_id = $1",
+		"filters":       "SELECT COUNT(*) FROM user_filters WHERE user_id = $1",
+		"sessions":      "SELECT COUNT(*) FROM refresh_tokens WHERE user_id = $1",
+	}
+	for name, query := range leftovers {
+		var n int
+		if err := s.db.QueryRow(query, u.ID).Scan(&n); err != nil {
+			t.Fatalf("couldn't count %s: %v", name, err)
+		}
+		if n != 0 {
+			t.Fatalf("expected the purge to remove the user's %s, found %d", name, n)
+		}
+	}
+
+	var got testChirp
+	s.expect(t, http.StatusOK, "GET", "/api/chirps/"+theirs.ID, "", nil, &got)
+	if got.LikeCount != 0 {
+		t.Fatalf("expected the purged user's like to be uncounted, got %d", got.LikeCount)
+	}
+
+	var left, anonymous int
+	err := s.db.QueryRow(
+		"SELECT (SELECT COUNT(*) FROM users WHERE id = $1), (SELECT COUNT(*) FROM moderation_actions WHERE user_id = $2 AND actor_id IS NULL)",
+		mod.ID, suspended.ID,
+	).Scan(&left, &anonymous)
+	if err != nil || left != 0 || anonymous != 1 {
+		t.Fatalf("expected the moderator erased and their log entry kept without them, got %d users and %d entries (%v)", left, anonymous, err)
+	}
+}
@@ -1,7 +1,17 @@
 package auth_test
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -28,16 +38,27 @@ func TestPasswordHashing(t *testing.T) {
 	}
 }
 
+// hmacKeyring returns a keyring with a single HS256 key, as when only
+// JWT_SECRET is configured.
+func hmacKeyring(t *testing.T, secret string) *auth.Keyring {
+	t.Helper()
+	keys, err := auth.NewKeyring(auth.NewHMACKey("", []byte(secret)), time.Hour)
+	if err != nil {
+		t.Fatalf("failed to make keyring: %v", err)
+	}
+	return keys
+}
+
 func TestJWTLifecycle(t *testing.T) {
-	secret := "supersecret"
+	keys := hmacKeyring(t, "supersecret")
 	userID := uuid.New()
 
-	token, err := auth.MakeJWT(userID, secret, time.Hour)
+	token, err := auth.MakeJWT(userID, auth.RoleUser, uuid.Nil, 0, keys, time.Hour)
 	if err != nil {
 		t.Fatalf("failed to make jwt: %v", err)
 	}
 
-	gotID, err := auth.ValidateJWT(token, secret)
+	gotID, err := auth.ValidateJWT(token, keys)
 	if err != nil {
 		t.Fatalf("failed to validate jwt: %v", err)
 	}
@@ -48,15 +69,15 @@ func TestJWTLifecycle(t *testing.T) {
 }
 
 func TestJWTExpired(t *testing.T) {
-	secret := "abc123"
+	keys := hmacKeyring(t, "abc123")
 	userID := uuid.New()
 
-	token, err := auth.MakeJWT(userID, secret, -time.Hour) // already expired
+	token, err := auth.MakeJWT(userID, auth.RoleUser, uuid.Nil, 0, keys, -time.Hour) // already expired
 	if err != nil {
 		t.Fatalf("make jwt failed: %v", err)
 	}
 
-	_, err = auth.ValidateJWT(token, secret)
+	_, err = auth.ValidateJWT(token, keys)
 	if err == nil {
 		t.Fatalf("expected error for expired token, got none")
 	}
@@ -65,17 +86,59 @@ func TestJWTExpired(t *testing.T) {
 func TestJWTWrongSecret(t *testing.T) {
 	userID := uuid.New()
 
-	token, err := auth.MakeJWT(userID, "correctSecret", time.Hour)
+	token, err := auth.MakeJWT(userID, auth.RoleUser, uuid.Nil, 0, hmacKeyring(t, "correctSecret"), time.Hour)
 	if err != nil {
 		t.Fatalf("make jwt failed: %v", err)
 	}
 
-	_, err = auth.ValidateJWT(token, "wrongSecret")
+	_, err = auth.ValidateJWT(token, hmacKeyring(t, "wrongSecret"))
 	if err == nil {
 		t.Fatalf("expected signature error but got none")
 	}
 }
 
+func TestJWTRoleClaim(t *testing.T) {
+	keys := hmacKeyring(t, "supersecret")
+	userID := uuid.New()
+
+	token, err := auth.MakeJWT(userID, auth.RoleModerator, uuid.Nil, 0, keys, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to make jwt: %v", err)
+	}
+
+	gotID, role, err := auth.ParseJWT(token, keys)
+	if err != nil {
+		t.Fatalf("failed to parse jwt: %v", err)
+	}
+	if gotID != userID || role != auth.RoleModerator {
+		t.Fatalf("expected %v/%v got %v/%v", userID, auth.RoleModerator, gotID, role)
+	}
+
+	// Tokens without a role claim are plain users
+	token, err = auth.MakeJWT(userID, "", uuid.Nil, 0, keys, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to make jwt: %v", err)
+	}
+	if _, role, err = auth.ParseJWT(token, keys); err != nil || role != auth.RoleUser {
+		t.Fatalf("expected %v got %v (%v)", auth.RoleUser, role, err)
+	}
+}
+
+func TestRoleAtLeast(t *testing.T) {
+	if !auth.RoleAdmin.AtLeast(auth.RoleModerator) {
+		t.Fatalf("admin should include moderator")
+	}
+	if auth.RoleModerator.AtLeast(auth.RoleAdmin) {
+		t.Fatalf("moderator should not include admin")
+	}
+	if auth.Role("owner").AtLeast(auth.RoleUser) {
+		t.Fatalf("unknown roles should grant nothing")
+	}
+	if _, err := auth.ParseRole("owner"); err == nil {
+		t.Fatalf("expected an error for an unknown role")
+	}
+}
+
 func TestGetBearerToken(t *testing.T) {
 	headers := http.Header{}
 	headers.Set("Authorization", "Bearer abc123")
@@ -107,3 +170,362 @@ func TestGetBearerToken_BadFormat(t *testing.T) {
 		t.Fatalf("expected error for malformed header")
 	}
 }
+
+func TestOneTimeToken(t *testing.T) {
+	token, hash, err := auth.MakeOneTimeToken()
+	if err != nil {
+		t.Fatalf("failed to make token: %v", err)
+	}
+	if token == hash || auth.HashOneTimeToken(token) != hash {
+		t.Fatalf("expected the stored hash to match the token")
+	}
+}
+
+func TestHashRefreshToken(t *testing.T) {
+	// RFC 4231 test case 2
+	got := auth.HashRefreshToken("what do ya want for nothing?", "Jefe")
+	want := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+	if auth.HashRefreshToken("token", "key-a") == auth.HashRefreshToken("token", "key-b") {
+		t.Fatalf("expected different keys to give different hashes")
+	}
+}
+
+func TestJWTSession(t *testing.T) {
+	userID := uuid.New()
+	sessionID := uuid.New()
+	keys := hmacKeyring(t, "mySecret")
+
+	token, err := auth.MakeJWT(userID, auth.RoleUser, sessionID, 0, keys, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to make JWT: %v", err)
+	}
+	claims, err := auth.ParseClaims(token, keys)
+	if err != nil {
+		t.Fatalf("expected valid token, got error: %v", err)
+	}
+	if got, _ := claims.UserID(); got != userID {
+		t.Fatalf("expected user %v, got %v", userID, got)
+	}
+	if claims.Session() != sessionID {
+		t.Fatalf("expected session %v, got %v", sessionID, claims.Session())
+	}
+
+	token, _ = auth.MakeJWT(userID, auth.RoleUser, uuid.Nil, 0, keys, time.Hour)
+	if claims, err = auth.ParseClaims(token, keys); err != nil || claims.Session() != uuid.Nil {
+		t.Fatalf("expected no session, got %v (%v)", claims, err)
+	}
+}
+
+func pemKey(t *testing.T, key interface{}) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestKeyringRotation(t *testing.T) {
+	userID := uuid.New()
+	oldKey := auth.NewHMACKey("old", []byte("old-secret"))
+	oldKeys, err := auth.NewKeyring(oldKey, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to make keyring: %v", err)
+	}
+	token, err := auth.MakeJWT(userID, auth.RoleUser, uuid.Nil, 0, oldKeys, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to make jwt: %v", err)
+	}
+
+	// Rotate: the old key is still accepted during the grace period
+	oldKey.RetiredAt = time.Now()
+	newKeys, err := auth.NewKeyring(auth.NewHMACKey("new", []byte("new-secret")), time.Hour, oldKey)
+	if err != nil {
+		t.Fatalf("failed to make keyring: %v", err)
+	}
+	if got, err := auth.ValidateJWT(token, newKeys); err != nil || got != userID {
+		t.Fatalf("expected old token to verify during grace, got %v (%v)", got, err)
+	}
+
+	// ...but not after it
+	oldKey.RetiredAt = time.Now().Add(-2 * time.Hour)
+	expiredKeys, _ := auth.NewKeyring(auth.NewHMACKey("new", []byte("new-secret")), time.Hour, oldKey)
+	if _, err := auth.ValidateJWT(token, expiredKeys); !errors.Is(err, auth.ErrRetiredKey) {
+		t.Fatalf("expected retired key error, got %v", err)
+	}
+
+	// Tokens from keys the keyring has never heard of are refused
+	otherKeys, _ := auth.NewKeyring(auth.NewHMACKey("other", []byte("old-secret")), time.Hour)
+	if _, err := auth.ValidateJWT(token, otherKeys); !errors.Is(err, auth.ErrUnknownKey) {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+}
+
+func TestKeyringAsymmetric(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+
+	for _, tc := range []struct {
+		alg auth.Algorithm
+		pem []byte
+	}{
+		{auth.AlgRS256, pemKey(t, rsaKey)},
+		{auth.AlgEdDSA, pemKey(t, edKey)},
+	} {
+		key, err := auth.ParseKeyPEM("k1", tc.alg, tc.pem)
+		if err != nil {
+			t.Fatalf("%s: failed to parse key: %v", tc.alg, err)
+		}
+		keys, err := auth.NewKeyring(key, time.Hour)
+		if err != nil {
+			t.Fatalf("%s: failed to make keyring: %v", tc.alg, err)
+		}
+
+		userID := uuid.New()
+		token, err := auth.MakeJWT(userID, auth.RoleUser, uuid.Nil, 0, keys, time.Hour)
+		if err != nil {
+			t.Fatalf("%s: failed to make jwt: %v", tc.alg, err)
+		}
+		if got, err := auth.ValidateJWT(token, keys); err != nil || got != userID {
+			t.Fatalf("%s: expected %v, got %v (%v)", tc.alg, userID, got, err)
+		}
+
+		jwks := keys.JWKS()
+		if len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != "k1" || jwks.Keys[0].Algorithm != string(tc.alg) {
+			t.Fatalf("%s: unexpected JWKS %+v", tc.alg, jwks)
+		}
+	}
+
+	// An HS256 token claiming an asymmetric key's kid must not verify
+	key, _ := auth.ParseKeyPEM("k1", auth.AlgRS256, pemKey(t, rsaKey))
+	rsaKeys, _ := auth.NewKeyring(key, time.Hour)
+	hmacKeys, _ := auth.NewKeyring(auth.NewHMACKey("k1", []byte("secret")), time.Hour)
+	token, _ := auth.MakeJWT(uuid.New(), auth.RoleUser, uuid.Nil, 0, hmacKeys, time.Hour)
+	if _, err := auth.ValidateJWT(token, rsaKeys); err == nil {
+		t.Fatalf("expected algorithm mismatch to be refused")
+	}
+
+	// HMAC secrets are never published
+	if jwks := hmacKeys.JWKS(); len(jwks.Keys) != 0 {
+		t.Fatalf("expected no published keys, got %+v", jwks)
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	dir := t.TempDir()
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	if err := os.WriteFile(filepath.Join(dir, "ed.pem"), pemKey(t, edKey), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	file := `{
+		"active": "2025-ed",
+		"keys": [
+			{"kid": "2025-ed", "alg": "EdDSA", "key_file": "ed.pem"},
+			{"kid": "2024-hs", "alg": "HS256", "secret": "old-secret", "retired_at": "2025-01-01T00:00:00Z"}
+		]
+	}`
+	path := filepath.Join(dir, "keys.json")
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+
+	keys, err := auth.LoadKeyring(path, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to load keyring: %v", err)
+	}
+	token, err := auth.MakeJWT(uuid.New(), auth.RoleUser, uuid.Nil, 0, keys, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to make jwt: %v", err)
+	}
+	if _, err := auth.ValidateJWT(token, keys); err != nil {
+		t.Fatalf("expected token to verify, got %v", err)
+	}
+
+	// Tokens from the long-retired HS256 key are refused
+	old, _ := auth.NewKeyring(auth.NewHMACKey("2024-hs", []byte("old-secret")), time.Hour)
+	token, _ = auth.MakeJWT(uuid.New(), auth.RoleUser, uuid.Nil, 0, old, time.Hour)
+	if _, err := auth.ValidateJWT(token, keys); !errors.Is(err, auth.ErrRetiredKey) {
+		t.Fatalf("expected retired key error, got %v", err)
+	}
+}
+
+func TestJWTIDAndVersion(t *testing.T) {
+	keys := hmacKeyring(t, "mySecret")
+	userID := uuid.New()
+
+	first, _ := auth.MakeJWT(userID, auth.RoleUser, uuid.Nil, 3, keys, time.Hour)
+	second, _ := auth.MakeJWT(userID, auth.RoleUser, uuid.Nil, 3, keys, time.Hour)
+	a, err := auth.ParseClaims(first, keys)
+	if err != nil {
+		t.Fatalf("expected valid token, got error: %v", err)
+	}
+	b, err := auth.ParseClaims(second, keys)
+	if err != nil {
+		t.Fatalf("expected valid token, got error: %v", err)
+	}
+	if a.ID == "" || a.ID == b.ID {
+		t.Fatalf("expected unique token IDs, got %q and %q", a.ID, b.ID)
+	}
+	if a.Version != 3 {
+		t.Fatalf("expected version 3, got %d", a.Version)
+	}
+}
+
+type testTokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func TestRefreshRotation(t *testing.T) {
+	s := startServer(t)
+	u := s.newUser(t)
+
+	var first, second testTokens
+	s.expect(t, http.StatusOK, "POST", "/api/refresh", u.RefreshToken, nil, &first)
+	if first.RefreshToken == "" || first.RefreshToken == u.RefreshToken {
+		t.Fatalf("expected a new refresh token, got %q", first.RefreshToken)
+	}
+	s.expect(t, http.StatusOK, "POST", "/api/refresh", first.RefreshToken, nil, &second)
+	s.expect(t, http.StatusOK, "GET", "/api/sessions", second.Token, nil, nil)
+}
+
+func TestLegacyRefreshTokenIsRehashed(t *testing.T) {
+	s := startServer(t)
+	u := s.newUser(t)
+
+	// Store the token in the clear, as it was before tokens were hashed
+	_, err := s.db.Exec("UPDATE refresh_tokens SET token_hash = 'legacy:' || $1 WHERE token_hash = $2",
+		u.RefreshToken, auth.HashRefreshToken(u.RefreshToken, testRefreshSecret))
+	if err != nil {
+		t.Fatalf("couldn't store a legacy token: %v", err)
+	}
+
+	s.expect(t, http.StatusOK, "POST", "/api/refresh", u.RefreshToken, nil, nil)
+
+	var legacy int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM refresh_tokens WHERE token_hash LIKE 'legacy:%' AND user_id = $1", u.ID).Scan(&legacy); err != nil || legacy != 0 {
+		t.Fatalf("expected the legacy token to be rehashed, found %d (%v)", legacy, err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	s := startServer(t)
+	u := s.newUser(t)
+	other := u
+	s.expect(t, http.StatusOK, "POST", "/api/login", "", map[string]string{"email": u.Email, "password": u.Password}, &other)
+
+	var next testTokens
+	s.expect(t, http.StatusOK, "POST", "/api/refresh", u.RefreshToken, nil, &next)
+
+	// Past REFRESH_REUSE_GRACE, which the test server sets to 1s
+	time.Sleep(1500 * time.Millisecond)
+	if msg := s.errorMessage(t, "POST", "/api/refresh", u.RefreshToken); msg != "Refresh token reuse detected" {
+		t.Fatalf("expected reuse to be detected, got %q", msg)
+	}
+
+	// The whole family is signed out, access tokens included
+	s.expect(t, http.StatusUnauthorized, "POST", "/api/refresh", next.RefreshToken, nil, nil)
+	s.expect(t, http.StatusUnauthorized, "GET", "/api/sessions", next.Token, nil, nil)
+
+	// Other sessions carry on
+	s.expect(t, http.StatusOK, "POST", "/api/refresh", other.RefreshToken, nil, nil)
+}
+
+func TestConcurrentRefreshWithinGrace(t *testing.T) {
+	s := startServer(t)
+	u := s.newUser(t)
+
+	var wg sync.WaitGroup
+	results := make([]testTokens, 2)
+	codes := make([]int, 2)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req, _ := http.NewRequest("POST", s.url+"/api/refresh", nil)
+			req.Header.Set("Authorization", "Bearer "+u.RefreshToken)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+			codes[i] = resp.StatusCode
+			json.NewDecoder(resp.Body).Decode(&results[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Fatalf("expected both concurrent refreshes to succeed, got %v", codes)
+		}
+		s.expect(t, http.StatusOK, "POST", "/api/refresh", results[i].RefreshToken, nil, nil)
+	}
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	s := startServer(t)
+	u := s.newUser(t)
+	other := u
+	s.expect(t, http.StatusOK, "POST", "/api/login", "", map[string]string{"email": u.Email, "password": u.Password}, &other)
+
+	// Seen once, so the server has the session cached as active
+	s.expect(t, http.StatusOK, "GET", "/api/sessions", other.Token, nil, nil)
+
+	var kept testTokens
+	s.expect(t, http.StatusOK, "DELETE", "/api/sessions", u.Token, nil, &kept)
+	s.expect(t, http.StatusUnauthorized, "GET", "/api/sessions", other.Token, nil, nil)
+	s.expect(t, http.StatusUnauthorized, "POST", "/api/refresh", other.RefreshToken, nil, nil)
+	// The caller's old access token goes too; the new one and the session's
+	// refresh token carry on
+	s.expect(t, http.StatusUnauthorized, "GET", "/api/sessions", u.Token, nil, nil)
+	s.expect(t, http.StatusOK, "GET", "/api/sessions", kept.Token, nil, nil)
+	s.expect(t, http.StatusOK, "POST", "/api/refresh", u.RefreshToken, nil, nil)
+}
+
+func TestSessionListedOnceWithSibling(t *testing.T) {
+	s := startServer(t)
+	u := s.newUser(t)
+
+	// Replaying the rotated token within the grace window gives the session
+	// a second live token
+	s.expect(t, http.StatusOK, "POST", "/api/refresh", u.RefreshToken, nil, nil)
+	s.expect(t, http.StatusOK, "POST", "/api/refresh", u.RefreshToken, nil, nil)
+
+	var sessions []struct {
+		ID string `json:"id"`
+	}
+	s.expect(t, http.StatusOK, "GET", "/api/sessions", u.Token, nil, &sessions)
+	if len(sessions) != 1 {
+		t.Fatalf("expected one session, got %+v", sessions)
+	}
+}
+
+func TestPasswordChangeStartsNewSession(t *testing.T) {
+	s := startServer(t)
+	u := s.newUser(t)
+	other := u
+	s.expect(t, http.StatusOK, "POST", "/api/login", "", map[string]string{"email": u.Email, "password": u.Password}, &other)
+
+	var changed testTokens
+	s.expect(t, http.StatusOK, "PUT", "/api/users", u.Token, map[string]string{"email": u.Email, "password": "a brand new password"}, &changed)
+	if changed.Token == "" || changed.RefreshToken == "" {
+		t.Fatalf("expected a new token pair, got %+v", changed)
+	}
+
+	for _, old := range []testUser{u, other} {
+		s.expect(t, http.StatusUnauthorized, "GET", "/api/sessions", old.Token, nil, nil)
+		s.expect(t, http.StatusUnauthorized, "POST", "/api/refresh", old.RefreshToken, nil, nil)
+	}
+	s.expect(t, http.StatusOK, "GET", "/api/sessions", changed.Token, nil, nil)
+	s.expect(t, http.StatusOK, "POST", "/api/refresh", changed.RefreshToken, nil, nil)
+}
@@ -0,0 +1,127 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/authcache"
+)
+
+// authcache.
+func TestAuthCache(t *testing.T) {
+	loads := 0
+	version := 1
+	cache := authcache.New(time.Hour, func(ctx context.Context, key uuid.UUID) (int, error) {
+		loads++
+		return version, nil
+	})
+	key := uuid.New()
+
+	for i := 0; i < 3; i++ {
+		if v, err := cache.Get(context.Background(), key); err != nil || v != 1 {
+			t.Fatalf("expected 1, got %v (%v)", v, err)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("expected one load, got %d", loads)
+	}
+
+	// Forgetting a key picks up changes at once
+	version = 2
+	cache.Forget(key)
+	if v, _ := cache.Get(context.Background(), key); v != 2 || loads != 2 {
+		t.Fatalf("expected a fresh load of 2, got %v after %d loads", v, loads)
+	}
+}
+
+func TestAuthCacheExpiry(t *testing.T) {
+	loads := 0
+	cache := authcache.New(10*time.Millisecond, func(ctx context.Context, key uuid.UUID) (bool, error) {
+		loads++
+		return true, nil
+	})
+	key := uuid.New()
+
+	cache.Get(context.Background(), key)
+	time.Sleep(20 * time.Millisecond)
+	cache.Get(context.Background(), key)
+	if loads != 2 {
+		t.Fatalf("expected the entry to expire, got %d loads", loads)
+	}
+}
+
+func TestAuthCacheErrors(t *testing.T) {
+	fail := true
+	cache := authcache.New(time.Hour, func(ctx context.Context, key uuid.UUID) (bool, error) {
+		if fail {
+			return false, errors.New("db down")
+		}
+		return true, nil
+	})
+	key := uuid.New()
+
+	if _, err := cache.Get(context.Background(), key); err == nil {
+		t.Fatalf("expected the loader's error")
+	}
+	fail = false
+	if v, err := cache.Get(context.Background(), key); err != nil || !v {
+		t.Fatalf("expected errors not to be cached, got %v (%v)", v, err)
+	}
+}
+
+func TestAuthCacheForgetDuringLoad(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	version := 1
+	cache := authcache.New(time.Hour, func(ctx context.Context, key uuid.UUID) (int, error) {
+		v := version
+		if v == 1 {
+			close(started)
+			<-release
+		}
+		return v, nil
+	})
+	key := uuid.New()
+
+	done := make(chan int)
+	go func() {
+		v, _ := cache.Get(context.Background(), key)
+		done <- v
+	}()
+
+	// The value changes while the first load is still reading the old one
+	<-started
+	version = 2
+	cache.Forget(key)
+	close(release)
+	<-done
+
+	if v, _ := cache.Get(context.Background(), key); v != 2 {
+		t.Fatalf("expected the stale load not to be cached, got %v", v)
+	}
+}
+
+func TestAuthCacheStaysWithinCap(t *testing.T) {
+	// One more key than the cache holds, none of them expired
+	const keys = 10000 + 1
+	loads := 0
+	cache := authcache.New(time.Hour, func(ctx context.Context, key uuid.UUID) (bool, error) {
+		loads++
+		return true, nil
+	})
+
+	all := make([]uuid.UUID, keys)
+	for i := range all {
+		all[i] = uuid.New()
+		cache.Get(context.Background(), all[i])
+	}
+	for _, key := range all {
+		cache.Get(context.Background(), key)
+	}
+	if loads == keys {
+		t.Fatalf("expected some entries to be evicted, got every one of %d cached", keys)
+	}
+}
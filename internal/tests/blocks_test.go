@@ -0,0 +1,109 @@
+package auth_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// blocks.
+func TestBlockHidesBothWays(t *testing.T) {
+	s := startServer(t)
+	blocker, blocked := s.newUser(t), s.newUser(t)
+	word := "w" + strings.ReplaceAll(uuid.NewString(), "-", "")
+
+	// Each follows the other before the block
+	s.expect(t, http.StatusNoContent, "POST", "/api/users/"+blocked.ID+"/follow", blocker.Token, nil, nil)
+	s.expect(t, http.StatusNoContent, "POST", "/api/users/"+blocker.ID+"/follow", blocked.Token, nil, nil)
+	chirps := map[string]testChirp{
+		blocker.ID: s.postChirp(t, blocker, word+" from the blocker"),
+		blocked.ID: s.postChirp(t, blocked, word+" from the blocked"),
+	}
+
+	s.expect(t, http.StatusNoContent, "POST", "/api/users/"+blocked.ID+"/block", blocker.Token, nil, nil)
+
+	cases := []struct {
+		name           string
+		viewer, author testUser
+	}{
+		{"blocker viewing blocked", blocker, blocked},
+		{"blocked viewing blocker", blocked, blocker},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			chirp := chirps[c.author.ID]
+
+			var timeline []testChirp
+			s.expect(t, http.StatusOK, "GET", "/api/timeline", c.viewer.Token, nil, &timeline)
+			if containsID(chirpIDs(timeline), chirp.ID) {
+				t.Fatalf("timeline shows a chirp across the block")
+			}
+
+			if msg := s.errorMessage(t, "GET", "/api/chirps/"+chirp.ID, c.viewer.Token); msg != "Chirp not found" {
+				t.Fatalf("expected a JSON not found error, got %q", msg)
+			}
+			if msg := s.errorMessage(t, "GET", "/api/chirps/"+chirp.ID+"/revisions", c.viewer.Token); msg != "Chirp not found" {
+				t.Fatalf("expected revisions across the block to be not found, got %q", msg)
+			}
+			if msg := s.errorMessage(t, "POST", "/api/chirps/"+chirp.ID+"/like", c.viewer.Token); msg != "Chirp not found" {
+				t.Fatalf("expected liking across the block to be not found, got %q", msg)
+			}
+
+			var results []testChirp
+			s.expect(t, http.StatusOK, "GET", "/api/chirps/search?q="+word, c.viewer.Token, nil, &results)
+			if ids := chirpIDs(results); len(ids) != 1 || ids[0] != chirps[c.viewer.ID].ID {
+				t.Fatalf("expected search to find only the viewer's own chirp, got %v", ids)
+			}
+
+			s.expect(t, http.StatusForbidden, "POST", "/api/users/"+c.author.ID+"/follow", c.viewer.Token, nil, nil)
+		})
+	}
+
+	// Unblocking restores visibility, but not the follows the block removed
+	s.expect(t, http.StatusNoContent, "DELETE", "/api/users/"+blocked.ID+"/block", blocker.Token, nil, nil)
+	s.expect(t, http.StatusOK, "GET", "/api/chirps/"+chirps[blocker.ID].ID, blocked.Token, nil, nil)
+	var following []struct {
+		UserID string `json:"user_id"`
+	}
+	s.expect(t, http.StatusOK, "GET", "/api/users/"+blocked.ID+"/following", "", nil, &following)
+	if len(following) != 0 {
+		t.Fatalf("expected the block to have removed follows, got %v", following)
+	}
+}
+
+func TestBlockHidesLikesAndNotifications(t *testing.T) {
+	s := startServer(t)
+	blocker, blocked := s.newUser(t), s.newUser(t)
+	chirp := s.postChirp(t, blocker, "liked before the block")
+	s.expect(t, http.StatusOK, "POST", "/api/chirps/"+chirp.ID+"/like", blocked.Token, nil, nil)
+
+	var notes struct {
+		UnreadCount int64 `json:"unread_count"`
+		Items       []struct {
+			ActorID string `json:"actor_id"`
+		} `json:"notifications"`
+	}
+	s.expect(t, http.StatusOK, "GET", "/api/notifications", blocker.Token, nil, &notes)
+	if len(notes.Items) != 1 || notes.Items[0].ActorID != blocked.ID {
+		t.Fatalf("expected a like notification before the block, got %+v", notes)
+	}
+
+	s.expect(t, http.StatusNoContent, "POST", "/api/users/"+blocked.ID+"/block", blocker.Token, nil, nil)
+
+	s.expect(t, http.StatusOK, "GET", "/api/notifications", blocker.Token, nil, &notes)
+	if len(notes.Items) != 0 || notes.UnreadCount != 0 {
+		t.Fatalf("expected notifications from the blocked user to be hidden, got %+v", notes)
+	}
+
+	var liked []testChirp
+	s.expect(t, http.StatusOK, "GET", "/api/users/"+blocked.ID+"/likes", blocker.Token, nil, &liked)
+	if len(liked) != 0 {
+		t.Fatalf("expected the likes listing to hide chirps across the block, got %v", chirpIDs(liked))
+	}
+	s.expect(t, http.StatusOK, "GET", "/api/users/"+blocked.ID+"/likes", blocked.Token, nil, &liked)
+	if len(liked) != 0 {
+		t.Fatalf("expected the blocked user's own likes to hide the blocker's chirp, got %v", chirpIDs(liked))
+	}
+}
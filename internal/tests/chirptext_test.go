@@ -0,0 +1,69 @@
+package auth_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/chirptext"
+)
+
+// chirptext.
+func TestChirpLengthCountsGraphemes(t *testing.T) {
+	cases := map[string]int{
+		"hello":         5,
+		"e\u0301":       1, // e + combining acute
+		"👨‍👩‍👧‍👦":       1, // family emoji, several code points
+		"🇵🇰":            1, // regional indicator flag
+		"مرحبا بالعالم": 13,
+	}
+
+	for in, want := range cases {
+		if got := chirptext.Length(in); got != want {
+			t.Fatalf("for %q expected %d got %d", in, want, got)
+		}
+	}
+}
+
+func TestValidateAllowsMultibyteUpToLimit(t *testing.T) {
+	v := chirptext.NewValidator(140)
+	body := strings.Repeat("😀", 140)
+	if _, err := v.Validate(body); err != nil {
+		t.Fatalf("expected 140 emoji to be valid, got %v", err)
+	}
+
+	_, err := v.Validate(body + "😀")
+	var verr *chirptext.ValidationError
+	if !errors.As(err, &verr) || len(verr.Violations) != 1 || verr.Violations[0].Code != chirptext.CodeTooLong {
+		t.Fatalf("expected a single too_long violation, got %v", err)
+	}
+	if verr.Violations[0].Length != 141 || verr.Violations[0].Limit != 140 {
+		t.Fatalf("unexpected length/limit: %+v", verr.Violations[0])
+	}
+}
+
+func TestValidateNormalizesToNFC(t *testing.T) {
+	got, err := chirptext.NewValidator(140).Validate("cafe\u0301")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "caf\u00e9" {
+		t.Fatalf("expected NFC form, got %q", got)
+	}
+}
+
+func TestValidateRejectsControlCharacters(t *testing.T) {
+	_, err := chirptext.NewValidator(140).Validate("ok\nfine\tbut\x00not\x1bthis")
+	var verr *chirptext.ValidationError
+	if !errors.As(err, &verr) || len(verr.Violations) != 2 {
+		t.Fatalf("expected two violations, got %v", err)
+	}
+	for _, v := range verr.Violations {
+		if v.Code != chirptext.CodeControlCharacter || v.Position == nil {
+			t.Fatalf("unexpected violation: %+v", v)
+		}
+	}
+	if *verr.Violations[0].Position != 11 {
+		t.Fatalf("expected first violation at 11, got %d", *verr.Violations[0].Position)
+	}
+}
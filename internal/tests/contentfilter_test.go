@@ -0,0 +1,33 @@
+package auth_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/contentfilter"
+)
+
+// contentfilter.
+func TestContentFilterNormalize(t *testing.T) {
+	words, err := contentfilter.NormalizeWords([]string{" Spoilers ", "spoilers", "Leak"})
+	if err != nil || !reflect.DeepEqual(words, []string{"spoilers", "leak"}) {
+		t.Fatalf("unexpected words %v (%v)", words, err)
+	}
+	for _, bad := range []string{"two words", "c++", "  "} {
+		if _, err := contentfilter.NormalizeWords([]string{bad}); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+
+	tags, err := contentfilter.NormalizeHashtags([]string{"#Finale", "finale", "go"})
+	if err != nil || !reflect.DeepEqual(tags, []string{"finale", "go"}) {
+		t.Fatalf("unexpected hashtags %v (%v)", tags, err)
+	}
+	if _, err := contentfilter.NormalizeHashtags([]string{"#1"}); err == nil {
+		t.Fatalf("expected a tag without letters to be rejected")
+	}
+
+	if _, err := contentfilter.NormalizeUsers([]string{"not-a-uuid"}); err == nil {
+		t.Fatalf("expected an invalid user ID to be rejected")
+	}
+}
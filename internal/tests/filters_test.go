@@ -0,0 +1,85 @@
+package auth_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// filters.
+func TestFiltersFillPages(t *testing.T) {
+	s := startServer(t)
+	author, viewer := s.newUser(t), s.newUser(t)
+
+	var kept []string
+	for i := 0; i < 3; i++ {
+		s.postChirp(t, author, "big SPOILERS!")
+		kept = append(kept, s.postChirp(t, author, "nothing to see").ID)
+	}
+	s.expect(t, http.StatusOK, "PUT", "/api/users/me/filters", viewer.Token, map[string]any{
+		"muted_words": []string{"spoilers"},
+	}, nil)
+
+	// Hidden chirps are left out before the limit, so the page is still full
+	var page []testChirp
+	s.expect(t, http.StatusOK, "GET", "/api/chirps?author_id="+author.ID+"&limit=3", viewer.Token, nil, &page)
+	if ids := chirpIDs(page); strings.Join(ids, ",") != strings.Join(kept, ",") {
+		t.Fatalf("expected the three unmuted chirps, got %v", ids)
+	}
+
+	// Without a token nothing is filtered
+	s.expect(t, http.StatusOK, "GET", "/api/chirps?author_id="+author.ID+"&limit=3", "", nil, &page)
+	if len(page) != 3 || page[0].Body != "big SPOILERS!" {
+		t.Fatalf("expected the anonymous page to start with a spoiler, got %+v", page)
+	}
+
+	s.expect(t, http.StatusOK, "PUT", "/api/users/me/filters", viewer.Token, map[string]any{
+		"muted_words": []string{"spoilers"},
+		"action":      "collapse",
+	}, nil)
+	s.expect(t, http.StatusOK, "GET", "/api/chirps?author_id="+author.ID+"&limit=2", viewer.Token, nil, &page)
+	if len(page) != 2 || !page[0].Filtered || page[1].Filtered {
+		t.Fatalf("expected the spoiler collapsed and the next chirp not, got %+v", page)
+	}
+}
+
+func TestFiltersApplyToSearchAndThreads(t *testing.T) {
+	s := startServer(t)
+	author, viewer := s.newUser(t), s.newUser(t)
+	word := "w" + strings.ReplaceAll(uuid.NewString(), "-", "")
+
+	root := s.postChirp(t, author, word+" root")
+	muted := s.postChirp(t, author, word+" #finale talk")
+	var reply, mutedReply testChirp
+	s.expect(t, http.StatusCreated, "POST", "/api/chirps", author.Token, map[string]string{"body": "fine reply", "in_reply_to": root.ID}, &reply)
+	s.expect(t, http.StatusCreated, "POST", "/api/chirps", author.Token, map[string]string{"body": "#finale reply", "in_reply_to": root.ID}, &mutedReply)
+
+	s.expect(t, http.StatusOK, "PUT", "/api/users/me/filters", viewer.Token, map[string]any{
+		"muted_hashtags": []string{"finale"},
+	}, nil)
+
+	var results []testChirp
+	s.expect(t, http.StatusOK, "GET", "/api/chirps/search?q="+word, viewer.Token, nil, &results)
+	if ids := chirpIDs(results); len(ids) != 1 || ids[0] != root.ID {
+		t.Fatalf("expected search to leave out %s, got %v", muted.ID, ids)
+	}
+
+	var thread struct {
+		Chirp struct {
+			ReplyCount int64 `json:"reply_count"`
+		} `json:"chirp"`
+		Replies []testChirp `json:"replies"`
+	}
+	s.expect(t, http.StatusOK, "GET", "/api/chirps/"+root.ID+"/thread", viewer.Token, nil, &thread)
+	if ids := chirpIDs(thread.Replies); len(ids) != 1 || ids[0] != reply.ID {
+		t.Fatalf("expected the thread to leave out %s, got %v", mutedReply.ID, ids)
+	}
+	if thread.Chirp.ReplyCount != 1 {
+		t.Fatalf("expected the reply count to leave out the muted reply, got %d", thread.Chirp.ReplyCount)
+	}
+	if thread.Replies[0].LikedByMe == nil {
+		t.Fatalf("expected thread replies to carry liked_by_me like any other chirp")
+	}
+}
@@ -0,0 +1,66 @@
+package auth_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+// follows.
+func TestFollowAndTimeline(t *testing.T) {
+	s := startServer(t)
+	alice, bob, carol := s.newUser(t), s.newUser(t), s.newUser(t)
+
+	first := s.postChirp(t, bob, "first from bob")
+	second := s.postChirp(t, bob, "second from bob")
+	stranger := s.postChirp(t, carol, "carol is not followed")
+
+	s.expect(t, http.StatusNoContent, "POST", "/api/users/"+bob.ID+"/follow", alice.Token, nil, nil)
+	// Following again is a no-op
+	s.expect(t, http.StatusNoContent, "POST", "/api/users/"+bob.ID+"/follow", alice.Token, nil, nil)
+
+	var timeline []testChirp
+	s.expect(t, http.StatusOK, "GET", "/api/timeline", alice.Token, nil, &timeline)
+	ids := chirpIDs(timeline)
+	if len(ids) != 2 || ids[0] != second.ID || ids[1] != first.ID {
+		t.Fatalf("expected bob's chirps newest first, got %v", ids)
+	}
+	if containsID(ids, stranger.ID) {
+		t.Fatalf("timeline includes a chirp from someone alice doesn't follow")
+	}
+
+	s.expect(t, http.StatusOK, "GET", "/api/timeline?limit=1", alice.Token, nil, &timeline)
+	if len(timeline) != 1 || timeline[0].ID != second.ID {
+		t.Fatalf("expected only the newest chirp, got %v", chirpIDs(timeline))
+	}
+
+	var followers []struct {
+		UserID string `json:"user_id"`
+	}
+	s.expect(t, http.StatusOK, "GET", "/api/users/"+bob.ID+"/followers", "", nil, &followers)
+	if len(followers) != 1 || followers[0].UserID != alice.ID {
+		t.Fatalf("expected alice to follow bob, got %v", followers)
+	}
+	s.expect(t, http.StatusOK, "GET", "/api/users/"+alice.ID+"/following", "", nil, &followers)
+	if len(followers) != 1 || followers[0].UserID != bob.ID {
+		t.Fatalf("expected alice to be following bob, got %v", followers)
+	}
+
+	s.expect(t, http.StatusNoContent, "DELETE", "/api/users/"+bob.ID+"/follow", alice.Token, nil, nil)
+	s.expect(t, http.StatusOK, "GET", "/api/timeline", alice.Token, nil, &timeline)
+	if len(timeline) != 0 {
+		t.Fatalf("expected an empty timeline after unfollowing, got %v", chirpIDs(timeline))
+	}
+}
+
+func TestFollowErrors(t *testing.T) {
+	s := startServer(t)
+	alice, bob := s.newUser(t), s.newUser(t)
+
+	s.expect(t, http.StatusUnauthorized, "POST", "/api/users/"+bob.ID+"/follow", "", nil, nil)
+	s.expect(t, http.StatusBadRequest, "POST", "/api/users/not-a-uuid/follow", alice.Token, nil, nil)
+	s.expect(t, http.StatusBadRequest, "POST", "/api/users/"+alice.ID+"/follow", alice.Token, nil, nil)
+	s.expect(t, http.StatusNotFound, "POST", "/api/users/00000000-0000-0000-0000-000000000000/follow", alice.Token, nil, nil)
+	s.expect(t, http.StatusNotFound, "DELETE", "/api/users/"+bob.ID+"/follow", alice.Token, nil, nil)
+	s.expect(t, http.StatusUnauthorized, "GET", "/api/timeline", "", nil, nil)
+	s.expect(t, http.StatusBadRequest, "GET", "/api/timeline?cursor=garbage", alice.Token, nil, nil)
+}
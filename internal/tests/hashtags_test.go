@@ -0,0 +1,56 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/entities"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/trending"
+)
+
+// hashtags.
+func TestExtractHashtags(t *testing.T) {
+	got := entities.ExtractHashtags("Loving #Go and #golang! #go again, not a#tag, not #123, #café_2")
+	want := []string{"go", "golang", "café_2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v got %v", want, got)
+	}
+}
+
+func TestTrendingCacheKeepsLastGoodRanking(t *testing.T) {
+	fail := false
+	cache := trending.NewCache(func(ctx context.Context) ([]trending.Tag, error) {
+		if fail {
+			return nil, errors.New("db down")
+		}
+		return []trending.Tag{{Tag: "go", Uses: 3, Score: 2.5}}, nil
+	})
+
+	if tags, computedAt := cache.Get(); tags != nil || !computedAt.IsZero() {
+		t.Fatalf("expected empty cache before first refresh")
+	}
+
+	if err := cache.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+
+	fail = true
+	if err := cache.Refresh(context.Background()); err == nil {
+		t.Fatalf("expected refresh error")
+	}
+
+	tags, computedAt := cache.Get()
+	if len(tags) != 1 || tags[0].Tag != "go" || computedAt.IsZero() {
+		t.Fatalf("expected previous ranking to be kept, got %v at %v", tags, computedAt)
+	}
+}
+
+func TestExtractMentions(t *testing.T) {
+	got := entities.ExtractMentions("Hey @Alice and @bob_2, cc @alice; mail me@example.com, not @42")
+	want := []string{"alice", "bob_2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v got %v", want, got)
+	}
+}
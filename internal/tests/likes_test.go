@@ -0,0 +1,127 @@
+package auth_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// likes.
+func TestLikeAndUnlike(t *testing.T) {
+	s := startServer(t)
+	author, fan := s.newUser(t), s.newUser(t)
+	chirp := s.postChirp(t, author, "like me")
+	path := "/api/chirps/" + chirp.ID + "/like"
+
+	var got testChirp
+	s.expect(t, http.StatusOK, "POST", path, fan.Token, nil, &got)
+	if got.LikeCount != 1 || got.LikedByMe == nil || !*got.LikedByMe {
+		t.Fatalf("after liking expected 1 like by me, got %d %v", got.LikeCount, got.LikedByMe)
+	}
+
+	// A second like is a no-op
+	s.expect(t, http.StatusOK, "POST", path, fan.Token, nil, &got)
+	if got.LikeCount != 1 {
+		t.Fatalf("double like expected count 1 got %d", got.LikeCount)
+	}
+
+	s.expect(t, http.StatusOK, "GET", "/api/chirps/"+chirp.ID, author.Token, nil, &got)
+	if got.LikeCount != 1 || got.LikedByMe == nil || *got.LikedByMe {
+		t.Fatalf("author expected 1 like not by them, got %d %v", got.LikeCount, got.LikedByMe)
+	}
+
+	var liked []testChirp
+	s.expect(t, http.StatusOK, "GET", "/api/users/"+fan.ID+"/likes", "", nil, &liked)
+	if ids := chirpIDs(liked); len(ids) != 1 || ids[0] != chirp.ID {
+		t.Fatalf("expected the fan's likes to be the chirp, got %v", ids)
+	}
+
+	s.expect(t, http.StatusOK, "DELETE", path, fan.Token, nil, &got)
+	if got.LikeCount != 0 || got.LikedByMe == nil || *got.LikedByMe {
+		t.Fatalf("after unliking expected 0 likes, got %d %v", got.LikeCount, got.LikedByMe)
+	}
+	// Unliking twice doesn't push the count below zero
+	s.expect(t, http.StatusOK, "DELETE", path, fan.Token, nil, &got)
+	if got.LikeCount != 0 {
+		t.Fatalf("double unlike expected count 0 got %d", got.LikeCount)
+	}
+	s.expect(t, http.StatusOK, "GET", "/api/users/"+fan.ID+"/likes", "", nil, &liked)
+	if len(liked) != 0 {
+		t.Fatalf("expected no likes after unliking, got %v", chirpIDs(liked))
+	}
+}
+
+func TestLikeErrors(t *testing.T) {
+	s := startServer(t)
+	u := s.newUser(t)
+	chirp := s.postChirp(t, u, "unlikeable")
+
+	s.expect(t, http.StatusUnauthorized, "POST", "/api/chirps/"+chirp.ID+"/like", "", nil, nil)
+	s.expect(t, http.StatusBadRequest, "POST", "/api/chirps/nope/like", u.Token, nil, nil)
+	s.expect(t, http.StatusNotFound, "POST", "/api/chirps/00000000-0000-0000-0000-000000000000/like", u.Token, nil, nil)
+	s.expect(t, http.StatusBadRequest, "GET", "/api/users/nope/likes", "", nil, nil)
+}
+
+func TestLikeCountUnderConcurrency(t *testing.T) {
+	s := startServer(t)
+	author := s.newUser(t)
+	chirp := s.postChirp(t, author, "popular")
+	path := "/api/chirps/" + chirp.ID + "/like"
+
+	fans := make([]testUser, 10)
+	for i := range fans {
+		fans[i] = s.newUser(t)
+	}
+
+	// Every fan likes twice at once; only one like each may count
+	var wg sync.WaitGroup
+	codes := make(chan int, 2*len(fans))
+	for _, fan := range fans {
+		for range 2 {
+			wg.Add(1)
+			go func(token string) {
+				defer wg.Done()
+				req, _ := http.NewRequest("POST", s.url+path, nil)
+				req.Header.Set("Authorization", "Bearer "+token)
+				resp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					codes <- 0
+					return
+				}
+				resp.Body.Close()
+				codes <- resp.StatusCode
+			}(fan.Token)
+		}
+	}
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Fatalf("concurrent like expected status 200 got %d", code)
+		}
+	}
+
+	var got testChirp
+	s.expect(t, http.StatusOK, "GET", "/api/chirps/"+chirp.ID, "", nil, &got)
+	if got.LikeCount != int32(len(fans)) {
+		t.Fatalf("expected %d likes got %d", len(fans), got.LikeCount)
+	}
+}
+
+func TestLikeCountFollowsCascadingDeletes(t *testing.T) {
+	s := startServer(t)
+	author, fan := s.newUser(t), s.newUser(t)
+	chirp := s.postChirp(t, author, "liked by someone who leaves")
+	s.expect(t, http.StatusOK, "POST", "/api/chirps/"+chirp.ID+"/like", fan.Token, nil, nil)
+
+	// Removing the user cascades to their chirp_likes rows
+	if _, err := s.db.Exec("DELETE FROM users WHERE id = $1", fan.ID); err != nil {
+		t.Fatalf("couldn't delete user: %v", err)
+	}
+
+	var got testChirp
+	s.expect(t, http.StatusOK, "GET", "/api/chirps/"+chirp.ID, "", nil, &got)
+	if got.LikeCount != 0 {
+		t.Fatalf("expected the like to be gone with its user, got %d", got.LikeCount)
+	}
+}
@@ -0,0 +1,122 @@
+package auth_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/mail"
+)
+
+// mail.
+func TestMailFormat(t *testing.T) {
+	date := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	got := string(mail.Format("chirpy@example.com", mail.Message{
+		To:      "alice@example.com",
+		Subject: "Hello",
+		Body:    "line one\nline two",
+	}, date))
+
+	for _, want := range []string{
+		"From: chirpy@example.com\r\n",
+		"To: alice@example.com\r\n",
+		"Subject: Hello\r\n",
+		"Date: Wed, 01 May 2024 12:00:00 +0000\r\n",
+		"\r\n\r\nline one\r\nline two\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in %q", want, got)
+		}
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.txt")
+	m := &mail.FileMailer{Path: path, From: "chirpy@example.com"}
+
+	for _, to := range []string{"a@example.com", "b@example.com"} {
+		if err := m.Send(context.Background(), mail.Message{To: to, Subject: "Hi", Body: "body"}); err != nil {
+			t.Fatalf("send failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if !strings.Contains(string(data), "To: a@example.com") || !strings.Contains(string(data), "To: b@example.com") {
+		t.Fatalf("expected both messages, got %q", data)
+	}
+
+	err = m.Send(context.Background(), mail.Message{To: "a@example.com\r\nBcc: x@example.com", Subject: "Hi"})
+	if err == nil {
+		t.Fatalf("expected header injection to be refused")
+	}
+}
+
+func TestSMTPMailerHonorsContext(t *testing.T) {
+	// A server that accepts connections and then never says a word
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("couldn't listen: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	m := &mail.SMTPMailer{Addr: l.Addr().String(), From: "Chirpy <chirpy@example.com>"}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := m.Send(ctx, mail.Message{To: "alice@example.com", Subject: "Hi", Body: "hi"}); err == nil {
+		t.Fatalf("expected an error from a server that never answers")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected Send to give up with its context, took %s", elapsed)
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	valid := map[string]string{
+		"alice@example.com":                 "alice@example.com",
+		"  bob.smith+tag@mail.example.org ": "bob.smith+tag@mail.example.org",
+		"o'neil@example.co.uk":              "o'neil@example.co.uk",
+	}
+	for in, want := range valid {
+		got, err := mail.ValidateAddress(in)
+		if err != nil || got != want {
+			t.Fatalf("ValidateAddress(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{
+		"",
+		"alice",
+		"alice@",
+		"@example.com",
+		"alice@localhost",
+		"alice@example..com",
+		"alice@example.com.",
+		"alice@[127.0.0.1]",
+		"Alice <alice@example.com>",
+		`"alice"@example.com`,
+		"alice@example.com, bob@example.com",
+		"alice\r\n@example.com",
+		strings.Repeat("a", 250) + "@example.com",
+	} {
+		if _, err := mail.ValidateAddress(in); err == nil {
+			t.Fatalf("expected %q to be rejected", in)
+		}
+	}
+}
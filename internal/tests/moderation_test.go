@@ -0,0 +1,85 @@
+package auth_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/moderation"
+)
+
+// moderation.
+func TestParseReason(t *testing.T) {
+	got, err := moderation.ParseReason(" Spam ")
+	if err != nil || got != moderation.ReasonSpam {
+		t.Fatalf("expected spam, got %q (%v)", got, err)
+	}
+	if _, err := moderation.ParseReason("boring"); err == nil {
+		t.Fatalf("expected an error for an unknown reason")
+	}
+}
+
+func TestParseModerationAction(t *testing.T) {
+	for _, s := range []string{"dismiss", "hide", "suspend"} {
+		if _, err := moderation.ParseAction(s); err != nil {
+			t.Fatalf("expected %q to parse, got %v", s, err)
+		}
+	}
+	if _, err := moderation.ParseAction("Hide"); err == nil {
+		t.Fatalf("expected actions to be case-sensitive")
+	}
+}
+
+func TestValidateReportDetails(t *testing.T) {
+	got, err := moderation.ValidateDetails(moderation.ReasonSpam, "  buy now  ")
+	if err != nil || got != "buy now" {
+		t.Fatalf("expected trimmed details, got %q (%v)", got, err)
+	}
+	if _, err := moderation.ValidateDetails(moderation.ReasonOther, "   "); !errors.Is(err, moderation.ErrOtherDetails) {
+		t.Fatalf("expected ErrOtherDetails, got %v", err)
+	}
+	long := strings.Repeat("é", moderation.MaxDetailsLength+1)
+	if _, err := moderation.ValidateDetails(moderation.ReasonSpam, long); !errors.Is(err, moderation.ErrDetailsLength) {
+		t.Fatalf("expected ErrDetailsLength, got %v", err)
+	}
+}
+
+func TestSuspendNeedsAHigherRole(t *testing.T) {
+	s := startServer(t)
+	mod, admin, user := s.newUser(t), s.newUser(t), s.newUser(t)
+	mod = s.withRole(t, mod, "moderator")
+	admin = s.withRole(t, admin, "admin")
+	reason := map[string]string{"reason": "testing"}
+
+	s.expect(t, http.StatusForbidden, "POST", "/admin/users/"+mod.ID+"/suspend", mod.Token, reason, nil)
+	s.expect(t, http.StatusForbidden, "POST", "/admin/users/"+admin.ID+"/suspend", mod.Token, reason, nil)
+	s.expect(t, http.StatusForbidden, "POST", "/admin/users/"+mod.ID+"/suspend", s.withRole(t, s.newUser(t), "moderator").Token, reason, nil)
+
+	var logged struct {
+		UserID  string `json:"user_id"`
+		ActorID string `json:"actor_id"`
+		Action  string `json:"action"`
+	}
+	s.expect(t, http.StatusOK, "POST", "/admin/users/"+user.ID+"/suspend", mod.Token, reason, &logged)
+	if logged.UserID != user.ID || logged.ActorID != mod.ID || logged.Action != "suspend" {
+		t.Fatalf("expected the suspension logged against the moderator, got %+v", logged)
+	}
+	s.expect(t, http.StatusOK, "POST", "/admin/users/"+mod.ID+"/suspend", admin.Token, reason, nil)
+	s.expect(t, http.StatusOK, "DELETE", "/admin/users/"+mod.ID+"/suspend", admin.Token, nil, nil)
+}
+
+func TestResolveReportsBySuspendingSignsOut(t *testing.T) {
+	s := startServer(t)
+	mod := s.withRole(t, s.newUser(t), "moderator")
+	author, reporter := s.newUser(t), s.newUser(t)
+	chirp := s.postChirp(t, author, "reported")
+	s.expect(t, http.StatusCreated, "POST", "/api/chirps/"+chirp.ID+"/report", reporter.Token, map[string]string{"reason": "spam"}, nil)
+
+	// Seen once, so the server has the author cached as active
+	s.expect(t, http.StatusOK, "GET", "/api/sessions", author.Token, nil, nil)
+
+	s.expect(t, http.StatusOK, "POST", "/admin/moderation/reports/"+chirp.ID+"/resolve", mod.Token, map[string]string{"action": "suspend"}, nil)
+	s.expect(t, http.StatusForbidden, "GET", "/api/sessions", author.Token, nil, nil)
+	s.expect(t, http.StatusUnauthorized, "POST", "/api/refresh", author.RefreshToken, nil, nil)
+}
@@ -0,0 +1,50 @@
+package auth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/pagination"
+	"github.com/google/uuid"
+)
+
+// pagination.
+func TestCursorRoundTrip(t *testing.T) {
+	want := pagination.Cursor{
+		CreatedAt: time.Date(2025, 12, 6, 10, 0, 0, 123456000, time.UTC),
+		ID:        uuid.New(),
+	}
+
+	got, err := pagination.DecodeCursor(pagination.EncodeCursor(want))
+	if err != nil {
+		t.Fatalf("failed to decode cursor: %v", err)
+	}
+
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Fatalf("expected %+v got %+v", want, got)
+	}
+}
+
+func TestCursorMalformed(t *testing.T) {
+	for _, s := range []string{"not base64!", "bm8tc2VwYXJhdG9y", ""} {
+		if _, err := pagination.DecodeCursor(s); err == nil {
+			t.Fatalf("expected error for cursor %q, got none", s)
+		}
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	limit, err := pagination.ParseLimit("", 20, 100)
+	if err != nil || limit != 20 {
+		t.Fatalf("expected default 20, got %d (%v)", limit, err)
+	}
+
+	limit, err = pagination.ParseLimit("500", 20, 100)
+	if err != nil || limit != 100 {
+		t.Fatalf("expected cap of 100, got %d (%v)", limit, err)
+	}
+
+	if _, err := pagination.ParseLimit("-1", 20, 100); err == nil {
+		t.Fatalf("expected error for negative limit")
+	}
+}
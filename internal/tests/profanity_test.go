@@ -0,0 +1,107 @@
+package auth_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/profanity"
+)
+
+// profanity.
+func TestProfanityMaskPreservesSpacing(t *testing.T) {
+	f := profanity.NewFilter([]string{"kerfuffle", "sharbert", "fornax"})
+
+	cases := map[string]string{
+		"what a kerfuffle!":            "what a ****!",
+		"KERFUFFLE  and\nSharbert":     "****  and\n****",
+		"k.e.r.f.u.f.f.l.e":            "****",
+		"k3rfuffl3 and f0rn4x":         "**** and ****",
+		"kerfuuuuffffle":               "****",
+		"fornax,sharbert":              "****,****",
+		"kerfuffles and fornaxes stay": "kerfuffles and fornaxes stay",
+		"nothing\tto   see\n\nhere":    "nothing\tto   see\n\nhere",
+	}
+
+	for in, want := range cases {
+		if got := profanity.Mask(in, f.Find(in)); got != want {
+			t.Fatalf("for %q expected %q got %q", in, want, got)
+		}
+	}
+}
+
+func TestProfanityLeetAtWordEdges(t *testing.T) {
+	f := profanity.NewFilter([]string{"sharbert", "ass"})
+
+	cases := map[string]string{
+		"$harbert":        "****",
+		"@ss":             "****",
+		"$harbert!":       "****!",
+		"(@ss), $harbert": "(****), ****",
+		"a$$ and @s":      "**** and @s",
+	}
+
+	for in, want := range cases {
+		if got := profanity.Mask(in, f.Find(in)); got != want {
+			t.Fatalf("for %q expected %q got %q", in, want, got)
+		}
+	}
+}
+
+func TestProfanityRepeatsDoNotShortenWords(t *testing.T) {
+	f := profanity.NewFilter([]string{"kerfuffle"})
+	if m := f.Find("kerfufle"); len(m) != 0 {
+		t.Fatalf("expected a shorter spelling not to match, got %v", m)
+	}
+}
+
+func TestProfanitySetWordsAtRuntime(t *testing.T) {
+	f := profanity.NewFilter(nil)
+	if m := f.Find("fornax"); len(m) != 0 {
+		t.Fatalf("expected no matches with an empty list, got %v", m)
+	}
+	f.SetWords([]string{"Fornax"})
+	if m := f.Find("fornax"); len(m) != 1 || m[0].Word != "fornax" {
+		t.Fatalf("expected fornax to match after SetWords, got %v", m)
+	}
+}
+
+func TestProfanityFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("# blocked\nKerfuffle\n\nfornax\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := &profanity.FileStore{Path: path}
+	ctx := context.Background()
+
+	if added, err := store.Add(ctx, "sharbert"); err != nil || !added {
+		t.Fatalf("expected sharbert to be added, got %v %v", added, err)
+	}
+	if removed, err := store.Remove(ctx, "fornax"); err != nil || !removed {
+		t.Fatalf("expected fornax to be removed, got %v %v", removed, err)
+	}
+	if removed, _ := store.Remove(ctx, "fornax"); removed {
+		t.Fatalf("expected removing a missing word to report false")
+	}
+
+	words, err := store.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"kerfuffle", "sharbert"}; !reflect.DeepEqual(words, want) {
+		t.Fatalf("expected %v got %v", want, words)
+	}
+}
+
+func TestParseProfanityMode(t *testing.T) {
+	for in, want := range map[string]profanity.Mode{"": profanity.ModeMask, "Reject": profanity.ModeReject, "flag": profanity.ModeFlag} {
+		if got, err := profanity.ParseMode(in); err != nil || got != want {
+			t.Fatalf("for %q expected %q got %q (%v)", in, want, got, err)
+		}
+	}
+	if _, err := profanity.ParseMode("shout"); err == nil {
+		t.Fatalf("expected an unknown mode to be rejected")
+	}
+}
@@ -0,0 +1,42 @@
+package auth_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/profile"
+)
+
+// profile.
+func TestValidateUsername(t *testing.T) {
+	cases := map[string]error{
+		"alice":                           nil,
+		"Bob_2":                           nil,
+		"ab":                              profile.ErrUsernameLength,
+		"a_very_long_username_over_30ch":  nil,
+		"a_very_long_username_over_30chr": profile.ErrUsernameLength,
+		"bad-name":                        profile.ErrUsernameChars,
+		"café":                            profile.ErrUsernameChars,
+		"12345":                           profile.ErrUsernameLetter,
+		"Admin":                           profile.ErrUsernameReserved,
+	}
+
+	for in, want := range cases {
+		if got := profile.ValidateUsername(in); !errors.Is(got, want) {
+			t.Fatalf("for %q expected %v got %v", in, want, got)
+		}
+	}
+}
+
+func TestValidateAvatarURL(t *testing.T) {
+	for _, ok := range []string{"", "https://example.com/a.png", "http://cdn.example.com/x"} {
+		if err := profile.ValidateAvatarURL(ok); err != nil {
+			t.Fatalf("expected %q to be valid, got %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"example.com/a.png", "javascript:alert(1)", "ftp://example.com/a"} {
+		if err := profile.ValidateAvatarURL(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package auth_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// rechirps.
+func TestRechirpAndQuote(t *testing.T) {
+	s := startServer(t)
+	author, fan, other := s.newUser(t), s.newUser(t), s.newUser(t)
+	original := s.postChirp(t, author, "worth repeating")
+	path := "/api/chirps/" + original.ID + "/rechirp"
+
+	var rechirp testChirp
+	s.expect(t, http.StatusCreated, "POST", path, fan.Token, nil, &rechirp)
+	if rechirp.RepostKind != "rechirp" || rechirp.Original == nil || rechirp.Original.ID != original.ID {
+		t.Fatalf("expected a rechirp embedding the original, got %+v", rechirp)
+	}
+	s.expect(t, http.StatusConflict, "POST", path, fan.Token, nil, nil)
+
+	// Rechirping a rechirp points at the chirp it rechirped
+	var again testChirp
+	s.expect(t, http.StatusCreated, "POST", "/api/chirps/"+rechirp.ID+"/rechirp", other.Token, nil, &again)
+	if again.Original == nil || again.Original.ID != original.ID {
+		t.Fatalf("expected the rechirp of a rechirp to embed the original, got %+v", again.Original)
+	}
+
+	var quote testChirp
+	s.expect(t, http.StatusCreated, "POST", path, fan.Token, map[string]string{"body": "so true"}, &quote)
+	if quote.RepostKind != "quote" || quote.Body != "so true" || quote.Original == nil || quote.Original.ID != original.ID {
+		t.Fatalf("expected a quote of the original, got %+v", quote)
+	}
+
+	// Deleting the original leaves the quote pointing at nothing
+	s.expect(t, http.StatusNoContent, "DELETE", "/api/chirps/"+original.ID, author.Token, nil, nil)
+	s.expect(t, http.StatusOK, "GET", "/api/chirps/"+quote.ID, "", nil, &quote)
+	if !quote.OriginalDeleted || quote.Original != nil {
+		t.Fatalf("expected the quote to report its original deleted, got %+v", quote)
+	}
+	s.expect(t, http.StatusNotFound, "POST", "/api/chirps/"+rechirp.ID+"/rechirp", author.Token, nil, nil)
+}
+
+func TestRechirpErrors(t *testing.T) {
+	s := startServer(t)
+	author, fan := s.newUser(t), s.newUser(t)
+	original := s.postChirp(t, author, "quote me")
+	path := "/api/chirps/" + original.ID + "/rechirp"
+
+	s.expect(t, http.StatusUnauthorized, "POST", path, "", nil, nil)
+	s.expect(t, http.StatusBadRequest, "POST", "/api/chirps/nope/rechirp", fan.Token, nil, nil)
+	s.expect(t, http.StatusNotFound, "POST", "/api/chirps/00000000-0000-0000-0000-000000000000/rechirp", fan.Token, nil, nil)
+	// Quotes follow the same length rules as chirps
+	s.expect(t, http.StatusBadRequest, "POST", path, fan.Token, map[string]string{"body": strings.Repeat("a", 141)}, nil)
+}
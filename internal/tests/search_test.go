@@ -0,0 +1,39 @@
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/search"
+)
+
+// search.
+func TestBuildTSQuery(t *testing.T) {
+	cases := map[string]string{
+		"go chirpy":              "go & chirpy",
+		`"hello world" chirp*`:   "(hello <-> world) & chirp:*",
+		"go OR rust -spam":       "(go | rust) & !spam",
+		"a OR b OR c d":          "(a | b | c) & d",
+		"go OR rust":             "go | rust",
+		"it's (fine) & | <-> !x": "its & fine & x",
+		"foo\tbar\nbaz":          "foo & bar & baz",
+		"go\tOR\nrust":           "go | rust",
+	}
+
+	for in, want := range cases {
+		got, err := search.BuildTSQuery(in)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", in, err)
+		}
+		if got != want {
+			t.Fatalf("for %q expected %q got %q", in, want, got)
+		}
+	}
+}
+
+func TestBuildTSQuery_Empty(t *testing.T) {
+	for _, in := range []string{"", "   ", `"" !?`} {
+		if _, err := search.BuildTSQuery(in); err == nil {
+			t.Fatalf("expected error for %q, got none", in)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package auth_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+)
+
+// server.
+// The tests that use startServer run the real server against the Postgres
+// database in CHIRPY_TEST_DB_URL, and are skipped when it is unset. The
+// database is wiped and migrated from sql/schema first, so point it at a
+// throwaway one.
+
+type testServer struct {
+	url string
+	db  *sql.DB
+	q   *database.Queries
+	dir string
+	cmd *exec.Cmd
+}
+
+var (
+	serverOnce sync.Once
+	server     *testServer
+	serverErr  error
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if server != nil {
+		server.close()
+	}
+	os.Exit(code)
+}
+
+// testRefreshSecret is the REFRESH_TOKEN_SECRET the test server runs with.
+const testRefreshSecret = "test-refresh-secret-at-least-32-bytes"
+
+// startServer returns the server shared by every test, starting it the first
+// time it is needed.
+func startServer(t *testing.T) *testServer {
+	t.Helper()
+	dbURL := os.Getenv("CHIRPY_TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("CHIRPY_TEST_DB_URL is not set")
+	}
+	serverOnce.Do(func() {
+		server, serverErr = newTestServer(dbURL)
+	})
+	if serverErr != nil {
+		t.Fatalf("couldn't start server: %v", serverErr)
+	}
+	return server
+}
+
+func newTestServer(dbURL string) (*testServer, error) {
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, err
+	}
+	s := &testServer{db: db, q: database.New(db)}
+	if err := migrate(db, filepath.Join(root, "sql", "schema")); err != nil {
+		s.close()
+		return nil, err
+	}
+
+	if s.dir, err = os.MkdirTemp("", "chirpy-test"); err != nil {
+		s.close()
+		return nil, err
+	}
+	bin := filepath.Join(s.dir, "chirpy")
+	build := exec.Command("go", "build", "-o", bin, ".")
+	build.Dir = root
+	if out, err := build.CombinedOutput(); err != nil {
+		s.close()
+		return nil, fmt.Errorf("go build: %v\n%s", err, out)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		s.close()
+		return nil, err
+	}
+	logPath := filepath.Join(s.dir, "server.log")
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		s.close()
+		return nil, err
+	}
+	defer logFile.Close()
+
+	s.url = "http://localhost:" + port
+	s.cmd = exec.Command(bin)
+	s.cmd.Dir = root
+	s.cmd.Env = append(os.Environ(),
+		"DB_URL="+dbURL,
+		"PORT="+port,
+		"PLATFORM=dev",
+		"JWT_SECRET=test-jwt-secret-that-is-at-least-32-bytes",
+		"REFRESH_TOKEN_SECRET="+testRefreshSecret,
+		"REFRESH_REUSE_GRACE=1s",
+		"POLKA_KEY=test-polka-key",
+		"MAILER=file",
+		"MAIL_FILE="+filepath.Join(s.dir, "mail.log"),
+	)
+	s.cmd.Stdout = logFile
+	s.cmd.Stderr = logFile
+	if err := s.cmd.Start(); err != nil {
+		s.close()
+		return nil, err
+	}
+
+	if err := s.waitReady(30 * time.Second); err != nil {
+		out, _ := os.ReadFile(logPath)
+		s.close()
+		return nil, fmt.Errorf("%v\n%s", err, out)
+	}
+	return s, nil
+}
+
+// migrate empties the public schema and applies the Up half of every
+// migration, in order.
+func migrate(db *sql.DB, dir string) error {
+	if _, err := db.Exec("DROP SCHEMA public CASCADE; CREATE SCHEMA public"); err != nil {
+		return fmt.Errorf("resetting schema: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		up, _, _ := strings.Cut(string(data), "-- +goose Down")
+		if _, err := db.Exec(up); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+	}
+	return nil
+}
+
+func freePort() (string, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	return port, err
+}
+
+func (s *testServer) waitReady(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(s.url + "/api/healthz")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("server not ready after %s", timeout)
+}
+
+func (s *testServer) close() {
+	if s.cmd != nil && s.cmd.Process != nil {
+		s.cmd.Process.Kill()
+		s.cmd.Wait()
+	}
+	s.db.Close()
+	if s.dir != "" {
+		os.RemoveAll(s.dir)
+	}
+}
+
+// request sends body as JSON and returns the response status. A successful
+// response is decoded into out when out is not nil.
+func (s *testServer) request(t *testing.T, method, path, token string, body, out any) int {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("couldn't encode request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, s.url+path, reader)
+	if err != nil {
+		t.Fatalf("couldn't build request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode < http.StatusMultipleChoices {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: couldn't decode response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// expect sends a request like request does and fails the test unless the
+// response has status want.
+func (s *testServer) expect(t *testing.T, want int, method, path, token string, body, out any) {
+	t.Helper()
+	if got := s.request(t, method, path, token, body, out); got != want {
+		t.Fatalf("%s %s: expected status %d got %d", method, path, want, got)
+	}
+}
+
+// errorMessage sends a request that is expected to fail and returns the
+// message of the JSON error it gets back.
+func (s *testServer) errorMessage(t *testing.T, method, path, token string) string {
+	t.Helper()
+	req, err := http.NewRequest(method, s.url+path, nil)
+	if err != nil {
+		t.Fatalf("couldn't build request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("%s %s: expected a JSON error, got status %d: %v", method, path, resp.StatusCode, err)
+	}
+	return body.Error
+}
+
+type testUser struct {
+	ID           string `json:"id"`
+	Email        string `json:"email"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	Password     string `json:"-"`
+}
+
+// newUser signs up a user with a unique email and logs them in.
+func (s *testServer) newUser(t *testing.T) testUser {
+	t.Helper()
+	creds := map[string]string{
+		"email":    "user-" + uuid.NewString() + "@example.com",
+		"password": "correct horse battery staple",
+	}
+	s.expect(t, http.StatusCreated, "POST", "/api/users", "", creds, nil)
+	var u testUser
+	s.expect(t, http.StatusOK, "POST", "/api/login", "", creds, &u)
+	u.Password = creds["password"]
+	return u
+}
+
+// withRole gives u a role and logs them in again, so their token carries it.
+func (s *testServer) withRole(t *testing.T, u testUser, role string) testUser {
+	t.Helper()
+	if _, err := s.db.Exec("UPDATE users SET role = $2 WHERE id = $1", u.ID, role); err != nil {
+		t.Fatalf("couldn't set role: %v", err)
+	}
+	creds := map[string]string{"email": u.Email, "password": u.Password}
+	s.expect(t, http.StatusOK, "POST", "/api/login", "", creds, &u)
+	return u
+}
+
+type testChirp struct {
+	ID              string     `json:"id"`
+	Body            string     `json:"body"`
+	UserID          string     `json:"user_id"`
+	LikeCount       int32      `json:"like_count"`
+	LikedByMe       *bool      `json:"liked_by_me"`
+	RepostKind      string     `json:"repost_kind"`
+	Original        *testChirp `json:"original"`
+	OriginalDeleted bool       `json:"original_deleted"`
+	Filtered        bool       `json:"filtered"`
+	Snippet         string     `json:"snippet"`
+}
+
+func (s *testServer) postChirp(t *testing.T, u testUser, body string) testChirp {
+	t.Helper()
+	var c testChirp
+	s.expect(t, http.StatusCreated, "POST", "/api/chirps", u.Token, map[string]string{"body": body}, &c)
+	return c
+}
+
+// chirpIDs lists the IDs of chirps, for comparing result sets.
+func chirpIDs(chirps []testChirp) []string {
+	ids := make([]string, len(chirps))
+	for i, c := range chirps {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+func containsID(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package trending
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Tag is one ranked hashtag.
+type Tag struct {
+	Tag   string  `json:"tag"`
+	Uses  int64   `json:"uses"`
+	Score float64 `json:"score"`
+}
+
+// Loader computes a fresh ranking.
+type Loader func(ctx context.Context) ([]Tag, error)
+
+// Cache holds the latest ranking so reads never touch the database.
+type Cache struct {
+	load Loader
+
+	mu         sync.RWMutex
+	tags       []Tag
+	computedAt time.Time
+}
+
+// NewCache returns an empty cache that fills itself through load.
+func NewCache(load Loader) *Cache {
+	return &Cache{load: load}
+}
+
+// Get returns the latest ranking and when it was computed. The zero time
+// means no ranking has been computed yet.
+func (c *Cache) Get() ([]Tag, time.Time) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tags, c.computedAt
+}
+
+// Refresh recomputes the ranking once. On error the previous ranking is kept.
+func (c *Cache) Refresh(ctx context.Context) error {
+	tags, err := c.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tags = tags
+	c.computedAt = time.Now().UTC()
+	return nil
+}
+
+// Run refreshes the ranking immediately and then every interval until ctx is done.
+func (c *Cache) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Refresh(ctx); err != nil {
+			log.Printf("Failed to refresh trending hashtags: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
+)
+
+// handlerJWKS publishes the public keys access tokens are signed with, so
+// other services can verify them without sharing a secret.
+func (cfg *apiConfig) handlerJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	respondWithJSON(w, http.StatusOK, cfg.jwtKeys.JWKS())
+}
+
+// newJWTKeyring builds the keyring access tokens are signed with. Without a
+// keys file, tokens are signed with secret as before. With one, secret is only
+// kept to verify tokens it signed until grace has passed since retiredAt, the
+// RFC 3339 time the keys file took over. It comes from the configuration
+// rather than the clock so restarts don't extend the grace period.
+func newJWTKeyring(secret, keysFile, retiredAt string, grace time.Duration) (*auth.Keyring, error) {
+	if keysFile == "" {
+		if secret == "" {
+			return nil, errors.New("JWT_SECRET or JWT_KEYS_FILE is required")
+		}
+		return auth.NewKeyring(auth.NewHMACKey("", []byte(secret)), grace)
+	}
+
+	var extra []auth.Key
+	if secret != "" {
+		if retiredAt == "" {
+			return nil, errors.New("JWT_LEGACY_RETIRED_AT is required when JWT_SECRET is kept alongside JWT_KEYS_FILE")
+		}
+		at, err := time.Parse(time.RFC3339, retiredAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT_LEGACY_RETIRED_AT: %w", err)
+		}
+		legacy := auth.NewHMACKey("", []byte(secret))
+		legacy.RetiredAt = at
+		extra = append(extra, legacy)
+	}
+	return auth.LoadKeyring(keysFile, grace, extra...)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/mail"
+)
+
+// newMailer builds the Mailer selected by the MAILER environment variable.
+// There is no default, so a server never silently drops mail; the log
+// mailer is only allowed when platform is "dev".
+func newMailer(platform string) (mail.Mailer, error) {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "Chirpy <no-reply@chirpy.local>"
+	}
+	switch kind := os.Getenv("MAILER"); kind {
+	case "":
+		return nil, errors.New("MAILER is required: smtp, file, or log when PLATFORM=dev")
+	case "log":
+		if platform != "dev" {
+			return nil, errors.New("MAILER=log is only allowed when PLATFORM=dev")
+		}
+		return mail.LogMailer{}, nil
+	case "file":
+		path := os.Getenv("MAIL_FILE")
+		if path == "" {
+			return nil, errors.New("MAIL_FILE is required when MAILER=file")
+		}
+		return &mail.FileMailer{Path: path, From: from}, nil
+	case "smtp":
+		addr := os.Getenv("SMTP_ADDR")
+		if addr == "" {
+			return nil, errors.New("SMTP_ADDR is required when MAILER=smtp")
+		}
+		return &mail.SMTPMailer{
+			Addr:     addr,
+			From:     from,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown mailer %q", kind)
+	}
+}
+
+// sendMail sends msg in the background so a slow mail server never holds up
+// a response. Failures can only be logged.
+func (cfg *apiConfig) sendMail(msg mail.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := cfg.mailer.Send(ctx, msg); err != nil {
+			log.Printf("Failed to send %q to %s: %v", msg.Subject, msg.To, err)
+		}
+	}()
+}
@@ -1,80 +1,251 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"sync/atomic"
+	"time"
 
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/authcache"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/chirptext"
 	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/mail"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/profanity"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/trending"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq" // Postgres driver
 	// SQLC generated package
 )
 
 type apiConfig struct {
-	fileserverHits atomic.Int32
-	dbQueries      *database.Queries
-	platform       string
-	jwtSecret      string
-	polkaKey       string
+	fileserverHits      atomic.Int32
+	db                  *sql.DB
+	dbQueries           *database.Queries
+	platform            string
+	jwtKeys             *auth.Keyring
+	authUsers           *authcache.Cache[database.User]
+	authSessions        *authcache.Cache[bool]
+	refreshSecret       string
+	refreshReuseGrace   time.Duration
+	polkaKey            string
+	chirpEditWindow     time.Duration
+	chirpValidator      chirptext.Validator
+	trending            *trending.Cache
+	adminAPIKey         string
+	bootstrapAdminEmail string
+	deletionGrace       time.Duration
+	passwordResetTTL    time.Duration
+	emailVerifyTTL      time.Duration
+	requireVerified     bool
+	mailer              mail.Mailer
+	profanity           *profanity.Filter
+	profanityStore      profanity.Store
+	profanityMode       profanity.Mode
 }
 
 func main() {
 	const filepathRoot = "."
-	const port = "8080"
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
 	// Load .env file
 	godotenv.Load()
 	// get db url and connect to db
 	dbURL := os.Getenv("DB_URL")
 	platform := os.Getenv("PLATFORM")
 	jwtSecret := os.Getenv("JWT_SECRET")
+	refreshSecret := os.Getenv("REFRESH_TOKEN_SECRET")
 	polkaKey := os.Getenv("POLKA_KEY")
+	adminAPIKey := os.Getenv("ADMIN_API_KEY")
+	chirpEditWindow := durationFromEnv("CHIRP_EDIT_WINDOW", 15*time.Minute)
+	chirpMaxLength := intFromEnv("CHIRP_MAX_LENGTH", chirptext.DefaultMaxLength)
+	trendingWindow := durationFromEnv("TRENDING_WINDOW", 24*time.Hour)
+	trendingHalfLife := durationFromEnv("TRENDING_HALF_LIFE", 6*time.Hour)
+	trendingRefresh := durationFromEnv("TRENDING_REFRESH_INTERVAL", time.Minute)
+	deletionGrace := durationFromEnv("ACCOUNT_DELETION_GRACE", 30*24*time.Hour)
+	passwordResetTTL := durationFromEnv("PASSWORD_RESET_TTL", 30*time.Minute)
+	emailVerifyTTL := durationFromEnv("EMAIL_VERIFICATION_TTL", 48*time.Hour)
+	authCacheTTL := durationFromEnv("AUTH_CACHE_TTL", 30*time.Second)
+	refreshReuseGrace := durationFromEnv("REFRESH_REUSE_GRACE", 10*time.Second)
 	db, err := sql.Open("postgres", dbURL)
 	if err != nil {
 		log.Fatalf("Failed to connect to DB: %v", err)
 	}
-	if jwtSecret == "" {
-		log.Fatal("JWT_SECRET is missing in .env")
+	jwtKeys, err := newJWTKeyring(jwtSecret, os.Getenv("JWT_KEYS_FILE"), os.Getenv("JWT_LEGACY_RETIRED_AT"), durationFromEnv("JWT_KEY_GRACE", time.Hour))
+	if err != nil {
+		log.Fatalf("Invalid JWT keys: %v", err)
+	}
+	if len(refreshSecret) < 32 {
+		log.Fatal("REFRESH_TOKEN_SECRET must be set to at least 32 characters; refresh tokens are stored as HMACs keyed with it")
 	}
 	if polkaKey == "" {
 		log.Fatal("POLKA_KEY is missing in .env")
 	}
 	dbQueries := database.New(db)
+	mailer, err := newMailer(platform)
+	if err != nil {
+		log.Fatalf("Invalid mailer configuration: %v", err)
+	}
+	profanityMode, err := profanity.ParseMode(os.Getenv("PROFANITY_MODE"))
+	if err != nil {
+		log.Fatalf("Invalid PROFANITY_MODE: %v", err)
+	}
+	// The word list lives in the database unless a file is configured
+	var profanityStore profanity.Store = dbWordStore{q: dbQueries}
+	if path := os.Getenv("PROFANITY_WORDS_FILE"); path != "" {
+		profanityStore = &profanity.FileStore{Path: path}
+	}
+	profanityWords, err := profanityStore.Load(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to load profanity word list: %v", err)
+	}
 	apiCfg := apiConfig{
-		fileserverHits: atomic.Int32{},
-		dbQueries:      dbQueries,
-		platform:       platform,
-		jwtSecret:      jwtSecret,
-		polkaKey:       polkaKey,
+		fileserverHits:      atomic.Int32{},
+		db:                  db,
+		dbQueries:           dbQueries,
+		platform:            platform,
+		jwtKeys:             jwtKeys,
+		authUsers:           authcache.New(authCacheTTL, dbQueries.GetUserByID),
+		authSessions:        authcache.New(authCacheTTL, dbQueries.IsSessionActive),
+		refreshSecret:       refreshSecret,
+		refreshReuseGrace:   refreshReuseGrace,
+		polkaKey:            polkaKey,
+		chirpEditWindow:     chirpEditWindow,
+		chirpValidator:      chirptext.NewValidator(chirpMaxLength),
+		trending:            trending.NewCache(trendingLoader(dbQueries, trendingWindow, trendingHalfLife)),
+		adminAPIKey:         adminAPIKey,
+		bootstrapAdminEmail: os.Getenv("BOOTSTRAP_ADMIN_EMAIL"),
+		deletionGrace:       deletionGrace,
+		passwordResetTTL:    passwordResetTTL,
+		emailVerifyTTL:      emailVerifyTTL,
+		requireVerified:     boolFromEnv("REQUIRE_VERIFIED_EMAIL", false),
+		mailer:              mailer,
+		profanity:           profanity.NewFilter(profanityWords),
+		profanityStore:      profanityStore,
+		profanityMode:       profanityMode,
 	}
 	defer db.Close()
+	go apiCfg.trending.Run(context.Background(), trendingRefresh)
+	go apiCfg.runAccountPurge(context.Background(), time.Hour)
 	// server and endpoints logic.
 	mux := http.NewServeMux()
 	fsHandler := apiCfg.middlewareMetricsInc(http.StripPrefix("/app", http.FileServer(http.Dir(filepathRoot))))
 	mux.Handle("/app/", fsHandler)
 
 	mux.HandleFunc("GET /api/healthz", handlerReadiness)
-	mux.HandleFunc("POST /api/validate_chirp", handlerChirpsValidate)
+	mux.HandleFunc("GET /.well-known/jwks.json", apiCfg.handlerJWKS)
+	mux.HandleFunc("POST /api/validate_chirp", apiCfg.handlerChirpsValidate)
 	mux.HandleFunc("POST /api/users", apiCfg.handlerCreateUser)
 	mux.HandleFunc("PUT /api/users", apiCfg.handlerUpdateUser)
+	mux.HandleFunc("GET /api/users/{username}", apiCfg.handlerGetUserProfile)
+	mux.HandleFunc("GET /api/users/me/filters", apiCfg.handlerGetUserFilters)
+	mux.HandleFunc("PUT /api/users/me/filters", apiCfg.handlerPutUserFilters)
+	mux.HandleFunc("POST /api/users/me/deactivate", apiCfg.handlerDeactivateAccount)
+	mux.HandleFunc("POST /api/users/me/reactivate", apiCfg.handlerReactivateAccount)
+	mux.HandleFunc("GET /api/users/me/export", apiCfg.handlerExportAccount)
+	mux.HandleFunc("DELETE /api/users/me", apiCfg.handlerDeleteAccount)
+	mux.HandleFunc("POST /api/users/{userID}/follow", apiCfg.handlerFollowUser)
+	mux.HandleFunc("DELETE /api/users/{userID}/follow", apiCfg.handlerUnfollowUser)
+	mux.HandleFunc("GET /api/users/{userID}/followers", apiCfg.handlerListFollowers)
+	mux.HandleFunc("GET /api/users/{userID}/following", apiCfg.handlerListFollowing)
+	mux.HandleFunc("GET /api/users/{userID}/likes", apiCfg.handlerListUserLikes)
+	mux.HandleFunc("POST /api/users/{userID}/block", apiCfg.handlerBlockUser)
+	mux.HandleFunc("DELETE /api/users/{userID}/block", apiCfg.handlerUnblockUser)
+	mux.HandleFunc("POST /api/users/{userID}/mute", apiCfg.handlerMuteUser)
+	mux.HandleFunc("DELETE /api/users/{userID}/mute", apiCfg.handlerUnmuteUser)
+	mux.HandleFunc("GET /api/users/me/blocks", apiCfg.handlerListBlocks)
+	mux.HandleFunc("GET /api/users/me/mutes", apiCfg.handlerListMutes)
 	mux.HandleFunc("POST /api/login", apiCfg.handlerLogin)
 	mux.HandleFunc("POST /api/refresh", apiCfg.handlerRefresh)
 	mux.HandleFunc("POST /api/revoke", apiCfg.handlerRevoke)
+	mux.HandleFunc("GET /api/sessions", apiCfg.handlerListSessions)
+	mux.HandleFunc("DELETE /api/sessions/{sessionID}", apiCfg.handlerRevokeSession)
+	mux.HandleFunc("DELETE /api/sessions", apiCfg.handlerRevokeOtherSessions)
+	mux.HandleFunc("POST /api/password/forgot", apiCfg.handlerForgotPassword)
+	mux.HandleFunc("POST /api/password/reset", apiCfg.handlerResetPassword)
+	mux.HandleFunc("POST /api/email/verify", apiCfg.handlerVerifyEmail)
+	mux.HandleFunc("POST /api/email/verify/resend", apiCfg.handlerResendVerification)
 	mux.HandleFunc("POST /api/chirps", apiCfg.handlerChirps)
+	mux.HandleFunc("PUT /api/chirps/{chirpID}", apiCfg.handlerUpdateChirp)
 	mux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.handlerDeleteChirp)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/revisions", apiCfg.handlerGetChirpRevisions)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/thread", apiCfg.handlerGetChirpThread)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/rechirp", apiCfg.handlerRechirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/like", apiCfg.handlerLikeChirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/like", apiCfg.handlerUnlikeChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/report", apiCfg.handlerReportChirp)
+	mux.HandleFunc("GET /api/chirps/search", apiCfg.handlerSearchChirps)
 	mux.HandleFunc("GET /api/chirps/", apiCfg.handlerGetChirpByID)
 	mux.HandleFunc("GET /api/chirps", apiCfg.handlerGetChirps)
+	mux.HandleFunc("GET /api/timeline", apiCfg.handlerTimeline)
+	mux.HandleFunc("GET /api/hashtags/{tag}/chirps", apiCfg.handlerGetHashtagChirps)
+	mux.HandleFunc("GET /api/trending", apiCfg.handlerTrending)
+	mux.HandleFunc("GET /api/notifications", apiCfg.handlerListNotifications)
+	mux.HandleFunc("POST /api/notifications/read", apiCfg.handlerMarkNotificationsRead)
 	mux.HandleFunc("POST /api/polka/webhooks", apiCfg.handlerPolkaWebhooks)
-	mux.HandleFunc("POST /admin/reset", apiCfg.handlerReset)
-	mux.HandleFunc("GET /admin/metrics", apiCfg.handlerMetrics)
+	mux.HandleFunc("POST /admin/reset", apiCfg.middlewareRequireRole(auth.RoleAdmin, apiCfg.handlerReset))
+	mux.HandleFunc("GET /admin/metrics", apiCfg.middlewareRequireRole(auth.RoleAdmin, apiCfg.handlerMetrics))
+	mux.HandleFunc("GET /admin/profanity/words", apiCfg.middlewareRequireRole(auth.RoleAdmin, apiCfg.handlerListProfanityWords))
+	mux.HandleFunc("POST /admin/profanity/words", apiCfg.middlewareRequireRole(auth.RoleAdmin, apiCfg.handlerAddProfanityWord))
+	mux.HandleFunc("DELETE /admin/profanity/words/{word}", apiCfg.middlewareRequireRole(auth.RoleAdmin, apiCfg.handlerDeleteProfanityWord))
+	mux.HandleFunc("GET /admin/profanity/flags", apiCfg.middlewareRequireRole(auth.RoleModerator, apiCfg.handlerListProfanityFlags))
+	mux.HandleFunc("DELETE /admin/profanity/flags/{chirpID}", apiCfg.middlewareRequireRole(auth.RoleModerator, apiCfg.handlerClearProfanityFlag))
+	mux.HandleFunc("GET /admin/moderation/reports", apiCfg.middlewareRequireRole(auth.RoleModerator, apiCfg.handlerListReportedChirps))
+	mux.HandleFunc("POST /admin/moderation/reports/{chirpID}/resolve", apiCfg.middlewareRequireRole(auth.RoleModerator, apiCfg.handlerResolveReports))
+	mux.HandleFunc("GET /admin/moderation/actions", apiCfg.middlewareRequireRole(auth.RoleModerator, apiCfg.handlerListModerationActions))
+	mux.HandleFunc("PUT /admin/users/{userID}/role", apiCfg.middlewareRequireRole(auth.RoleAdmin, apiCfg.handlerSetUserRole))
+	mux.HandleFunc("POST /admin/users/{userID}/suspend", apiCfg.middlewareRequireRole(auth.RoleModerator, apiCfg.handlerSuspendUser))
+	mux.HandleFunc("DELETE /admin/users/{userID}/suspend", apiCfg.middlewareRequireRole(auth.RoleModerator, apiCfg.handlerUnsuspendUser))
 
 	srv := &http.Server{
 		Addr:    ":" + port,
-		Handler: mux,
+		Handler: apiCfg.middlewareAuthenticate(mux),
 	}
 
 	log.Printf("Serving files from %s on port: %s\n", filepathRoot, port)
 	log.Fatal(srv.ListenAndServe())
 }
+
+// durationFromEnv reads a Go duration such as "15m" from the environment,
+// falling back to def when the variable is unset.
+func durationFromEnv(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", name, err)
+	}
+	return d
+}
+
+func intFromEnv(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		log.Fatalf("Invalid %s: %q", name, v)
+	}
+	return n
+}
+
+func boolFromEnv(name string, def bool) bool {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Fatalf("Invalid %s: %q", name, v)
+	}
+	return b
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/pagination"
+)
+
+// cursorParams decodes an optional ?cursor= value into the nullable
+// (created_at, id) pair the keyset queries expect.
+func cursorParams(s string) (sql.NullTime, uuid.NullUUID, error) {
+	if s == "" {
+		return sql.NullTime{}, uuid.NullUUID{}, nil
+	}
+	cursor, err := pagination.DecodeCursor(s)
+	if err != nil {
+		return sql.NullTime{}, uuid.NullUUID{}, err
+	}
+	return sql.NullTime{Time: cursor.CreatedAt, Valid: true}, uuid.NullUUID{UUID: cursor.ID, Valid: true}, nil
+}
+
+// setNextPageHeaders advertises the cursor for the next page both as a plain
+// header and as an RFC 8288 Link header pointing at the same URL.
+func setNextPageHeaders(w http.ResponseWriter, r *http.Request, nextCursor string) {
+	next := *r.URL
+	query := next.Query()
+	query.Set("cursor", nextCursor)
+	next.RawQuery = query.Encode()
+
+	w.Header().Set("X-Next-Cursor", nextCursor)
+	w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+}
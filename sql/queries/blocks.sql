@@ -0,0 +1,62 @@
+-- name: CreateBlock :execrows
+INSERT INTO user_blocks (blocker_id, blocked_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (blocker_id, blocked_id) DO NOTHING;
+
+-- name: DeleteBlock :execrows
+DELETE FROM user_blocks
+WHERE blocker_id = $1
+  AND blocked_id = $2;
+
+-- name: IsBlockedEitherWay :one
+-- True when either user has blocked the other.
+SELECT EXISTS (
+    SELECT 1
+    FROM user_blocks
+    WHERE (blocker_id = sqlc.arg('user_a') AND blocked_id = sqlc.arg('user_b'))
+       OR (blocker_id = sqlc.arg('user_b') AND blocked_id = sqlc.arg('user_a'))
+);
+
+-- name: ListBlockedUserIDs :many
+-- Of the given users, those who have blocked user_id or been blocked by them.
+SELECT blocked_id AS user_id
+FROM user_blocks
+WHERE blocker_id = sqlc.arg('user_id')
+  AND blocked_id = ANY(sqlc.arg('user_ids')::uuid[])
+UNION
+SELECT blocker_id
+FROM user_blocks
+WHERE blocked_id = sqlc.arg('user_id')
+  AND blocker_id = ANY(sqlc.arg('user_ids')::uuid[]);
+
+-- name: ListBlocksPage :many
+SELECT blocked_id AS user_id, created_at
+FROM user_blocks
+WHERE blocker_id = sqlc.arg('user_id')
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, blocked_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, blocked_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CreateMute :execrows
+INSERT INTO user_mutes (muter_id, muted_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (muter_id, muted_id) DO NOTHING;
+
+-- name: DeleteMute :execrows
+DELETE FROM user_mutes
+WHERE muter_id = $1
+  AND muted_id = $2;
+
+-- name: ListMutesPage :many
+SELECT muted_id AS user_id, created_at
+FROM user_mutes
+WHERE muter_id = sqlc.arg('user_id')
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, muted_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, muted_id DESC
+LIMIT sqlc.arg('limit');
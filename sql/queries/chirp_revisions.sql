@@ -0,0 +1,29 @@
+-- name: CreateChirpRevision :one
+INSERT INTO chirp_revisions (id, chirp_id, body, created_at, replaced_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    NOW()
+)
+RETURNING *;
+
+-- name: ListChirpRevisions :many
+SELECT *
+FROM chirp_revisions
+WHERE chirp_id = $1
+ORDER BY replaced_at ASC;
+
+-- name: ListRevisionsByUserPage :many
+-- Earlier bodies of every chirp the user wrote, newest first.
+SELECT chirp_revisions.*
+FROM chirp_revisions
+JOIN chirps ON chirps.id = chirp_revisions.chirp_id
+WHERE chirps.user_id = sqlc.arg('user_id')
+  AND (
+    sqlc.narg('cursor_replaced_at')::timestamp IS NULL
+    OR (chirp_revisions.replaced_at, chirp_revisions.id) < (sqlc.narg('cursor_replaced_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY chirp_revisions.replaced_at DESC, chirp_revisions.id DESC
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,67 @@
+-- name: CountChirpReplies :one
+SELECT visible_reply_count(sqlc.narg('viewer_id')::uuid, sqlc.arg('id')::uuid)::bigint AS reply_count;
+
+-- name: ListChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT chirps.id, chirps.parent_id, 1 AS depth
+    FROM chirps
+    WHERE chirps.id = (SELECT c.parent_id FROM chirps c WHERE c.id = sqlc.arg('id'))
+    UNION ALL
+    SELECT c.id, c.parent_id, a.depth + 1
+    FROM chirps c
+    JOIN ancestors a ON c.id = a.parent_id
+)
+SELECT
+    sqlc.embed(chirps),
+    visible_reply_count(sqlc.narg('viewer_id')::uuid, chirps.id)::bigint AS reply_count
+FROM ancestors a
+JOIN chirps ON chirps.id = a.id
+WHERE chirps.hidden_at IS NULL
+  AND chirps.user_id NOT IN (SELECT id FROM inactive_users)
+  AND chirp_filter_action(sqlc.narg('viewer_id')::uuid, chirps.id) IS DISTINCT FROM 'hide'
+  AND NOT hidden_by_relationship(sqlc.narg('viewer_id')::uuid, chirps.user_id)
+ORDER BY a.depth DESC;
+
+-- name: ListChirpRepliesPage :many
+SELECT
+    sqlc.embed(chirps),
+    visible_reply_count(sqlc.narg('viewer_id')::uuid, chirps.id)::bigint AS reply_count
+FROM chirps
+WHERE chirps.parent_id = sqlc.arg('parent_id')::uuid
+  AND chirps.hidden_at IS NULL
+  AND chirps.user_id NOT IN (SELECT id FROM inactive_users)
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (chirps.created_at, chirps.id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+  AND chirp_filter_action(sqlc.narg('viewer_id')::uuid, chirps.id) IS DISTINCT FROM 'hide'
+  AND NOT hidden_by_relationship(sqlc.narg('viewer_id')::uuid, chirps.user_id)
+ORDER BY chirps.created_at ASC, chirps.id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListChirpDescendants :many
+WITH RECURSIVE descendants AS (
+    SELECT chirps.id, 1 AS depth
+    FROM chirps
+    WHERE chirps.parent_id = ANY(sqlc.arg('root_ids')::uuid[])
+      AND chirps.hidden_at IS NULL
+      AND chirps.user_id NOT IN (SELECT id FROM inactive_users)
+      AND chirp_filter_action(sqlc.narg('viewer_id')::uuid, chirps.id) IS DISTINCT FROM 'hide'
+      AND NOT hidden_by_relationship(sqlc.narg('viewer_id')::uuid, chirps.user_id)
+    UNION ALL
+    SELECT c.id, d.depth + 1
+    FROM chirps c
+    JOIN descendants d ON c.parent_id = d.id
+    WHERE d.depth < sqlc.arg('max_depth')::int
+      AND c.hidden_at IS NULL
+      AND c.user_id NOT IN (SELECT id FROM inactive_users)
+      AND chirp_filter_action(sqlc.narg('viewer_id')::uuid, c.id) IS DISTINCT FROM 'hide'
+      AND NOT hidden_by_relationship(sqlc.narg('viewer_id')::uuid, c.user_id)
+)
+SELECT
+    sqlc.embed(chirps),
+    visible_reply_count(sqlc.narg('viewer_id')::uuid, chirps.id)::bigint AS reply_count
+FROM descendants d
+JOIN chirps ON chirps.id = d.id
+ORDER BY chirps.created_at ASC, chirps.id ASC
+LIMIT sqlc.arg('limit');
@@ -1,11 +1,14 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_id, original_id, repost_kind)
 VALUES (
     gen_random_uuid(),  -- id
     NOW(),              -- created_at
     NOW(),              -- updated_at
     $1,                 -- body
-    $2                  -- user_id
+    $2,                 -- user_id
+    $3,                 -- parent_id
+    $4,                 -- original_id
+    $5                  -- repost_kind
 )
 RETURNING *;
 
@@ -17,6 +20,25 @@ SELECT *
 FROM chirps
 WHERE id = $1;
 
+-- name: GetChirpByIDForUpdate :one
+SELECT *
+FROM chirps
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps
+SET
+    body = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ListChirpsByIDs :many
+SELECT *
+FROM chirps
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
+
 -- name: ListChirps :many
 SELECT *
 FROM chirps
@@ -28,7 +50,83 @@ FROM chirps
 WHERE user_id = $1
 ORDER BY created_at DESC;
 
+-- name: ListChirpsByUserPage :many
+-- Every chirp the user wrote, hidden ones included, newest first.
+SELECT *
+FROM chirps
+WHERE user_id = sqlc.arg('user_id')
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
 -- name: DeleteChirp :execrows
 DELETE FROM chirps
 WHERE id = $1
   AND user_id = $2;
+
+-- name: ListChirpsPageAsc :many
+SELECT *
+FROM chirps
+WHERE (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id')::uuid)
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+  AND hidden_at IS NULL
+  AND user_id NOT IN (SELECT id FROM inactive_users)
+  AND NOT hidden_by_relationship(sqlc.narg('viewer_id')::uuid, chirps.user_id)
+  -- Leave out chirps the viewer's filters hide
+  AND chirp_filter_action(sqlc.narg('viewer_id')::uuid, chirps.id) IS DISTINCT FROM 'hide'
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListChirpsPageDesc :many
+SELECT *
+FROM chirps
+WHERE (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id')::uuid)
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+  AND hidden_at IS NULL
+  AND user_id NOT IN (SELECT id FROM inactive_users)
+  AND NOT hidden_by_relationship(sqlc.narg('viewer_id')::uuid, chirps.user_id)
+  -- Leave out chirps the viewer's filters hide
+  AND chirp_filter_action(sqlc.narg('viewer_id')::uuid, chirps.id) IS DISTINCT FROM 'hide'
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: SearchChirps :many
+SELECT
+    sqlc.embed(chirps),
+    ts_rank_cd(search_vector, to_tsquery('english', sqlc.arg('query')))::real AS rank,
+    -- The body is HTML-escaped before highlighting so the only markup in the
+    -- snippet is the <mark> tags added here.
+    ts_headline(
+        'english',
+        replace(replace(replace(replace(replace(body,
+            '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'),
+        to_tsquery('english', sqlc.arg('query')),
+        'StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=5'
+    )::text AS snippet
+FROM chirps
+WHERE search_vector @@ to_tsquery('english', sqlc.arg('query'))
+  AND (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id')::uuid)
+  AND (sqlc.narg('since')::timestamp IS NULL OR created_at >= sqlc.narg('since')::timestamp)
+  AND (sqlc.narg('until')::timestamp IS NULL OR created_at < sqlc.narg('until')::timestamp)
+  AND hidden_at IS NULL
+  AND user_id NOT IN (SELECT id FROM inactive_users)
+  AND NOT hidden_by_relationship(sqlc.narg('viewer_id')::uuid, chirps.user_id)
+  -- Leave out chirps the viewer's filters hide
+  AND chirp_filter_action(sqlc.narg('viewer_id')::uuid, id) IS DISTINCT FROM 'hide'
+ORDER BY rank DESC, created_at DESC, id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: CountChirpsByUser :one
+SELECT COUNT(*)
+FROM chirps
+WHERE user_id = $1;
@@ -0,0 +1,50 @@
+-- name: UpsertHashtag :one
+INSERT INTO hashtags (id, tag, created_at)
+VALUES (gen_random_uuid(), $1, NOW())
+ON CONFLICT (tag) DO UPDATE SET tag = EXCLUDED.tag
+RETURNING *;
+
+-- name: CreateChirpHashtag :exec
+INSERT INTO chirp_hashtags (chirp_id, hashtag_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteChirpHashtags :exec
+DELETE FROM chirp_hashtags
+WHERE chirp_id = $1;
+
+-- name: ListChirpsByHashtagPage :many
+SELECT chirps.*
+FROM chirps
+JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+WHERE hashtags.tag = sqlc.arg('tag')
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+  AND chirps.hidden_at IS NULL
+  AND chirps.user_id NOT IN (SELECT id FROM inactive_users)
+  AND NOT hidden_by_relationship(sqlc.narg('viewer_id')::uuid, chirps.user_id)
+  -- Leave out chirps the viewer's filters hide
+  AND chirp_filter_action(sqlc.narg('viewer_id')::uuid, chirps.id) IS DISTINCT FROM 'hide'
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListTrendingHashtags :many
+-- Each use decays exponentially with age, halving every half_life_seconds.
+SELECT
+    hashtags.tag,
+    COUNT(*) AS uses,
+    SUM(
+        EXP(-LN(2) * EXTRACT(EPOCH FROM (LOCALTIMESTAMP - chirps.created_at)) / sqlc.arg('half_life_seconds')::float8)
+    )::float8 AS score
+FROM chirp_hashtags
+JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+JOIN chirps ON chirps.id = chirp_hashtags.chirp_id
+WHERE chirps.created_at >= LOCALTIMESTAMP - make_interval(secs => sqlc.arg('window_seconds')::float8)
+  AND chirps.hidden_at IS NULL
+  AND chirps.user_id NOT IN (SELECT id FROM inactive_users)
+GROUP BY hashtags.tag
+ORDER BY score DESC, uses DESC, hashtags.tag ASC
+LIMIT sqlc.arg('limit');
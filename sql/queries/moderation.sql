@@ -0,0 +1,86 @@
+-- name: CreateChirpReport :one
+INSERT INTO chirp_reports (id, chirp_id, reporter_id, reason, details, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, NOW())
+RETURNING *;
+
+-- name: ListReportedChirpsPage :many
+-- Chirps with open reports, longest-waiting first.
+SELECT sqlc.embed(chirps), COUNT(*) AS report_count, MIN(chirp_reports.created_at)::timestamp AS first_reported_at
+FROM chirp_reports
+JOIN chirps ON chirps.id = chirp_reports.chirp_id
+WHERE chirp_reports.resolved_at IS NULL
+GROUP BY chirps.id
+HAVING (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (MIN(chirp_reports.created_at), chirps.id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY first_reported_at, chirps.id
+LIMIT sqlc.arg('limit');
+
+-- name: ListOpenReportsByChirpIDs :many
+SELECT *
+FROM chirp_reports
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+  AND resolved_at IS NULL
+ORDER BY created_at, id;
+
+-- name: ListReportsByReporterPage :many
+SELECT *
+FROM chirp_reports
+WHERE reporter_id = sqlc.arg('user_id')
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ResolveChirpReports :execrows
+UPDATE chirp_reports
+SET
+    resolved_at = NOW(),
+    resolution = $2
+WHERE chirp_id = $1
+  AND resolved_at IS NULL;
+
+-- name: HideChirp :exec
+UPDATE chirps
+SET hidden_at = NOW()
+WHERE id = $1
+  AND hidden_at IS NULL;
+
+-- name: SuspendUser :execrows
+-- Suspending a suspended user replaces the reason and expiry.
+UPDATE users
+SET
+    status = 'suspended',
+    suspension_reason = $2,
+    suspended_until = $3,
+    updated_at = NOW()
+WHERE id = $1
+  AND status <> 'deleted';
+
+-- name: UnsuspendUser :execrows
+UPDATE users
+SET
+    status = 'active',
+    suspension_reason = '',
+    suspended_until = NULL,
+    updated_at = NOW()
+WHERE id = $1
+  AND status = 'suspended';
+
+-- name: CreateModerationAction :one
+INSERT INTO moderation_actions (id, chirp_id, user_id, actor_id, action, note, reports_resolved, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, NOW())
+RETURNING *;
+
+-- name: ListModerationActionsPage :many
+SELECT *
+FROM moderation_actions
+WHERE (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,51 @@
+-- name: CreateNotification :exec
+INSERT INTO notifications (id, user_id, actor_id, kind, chirp_id, created_at, read_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW(),
+    NULL
+);
+
+-- name: ListNotificationsPage :many
+SELECT *
+FROM notifications
+WHERE user_id = sqlc.arg('user_id')
+  AND (NOT sqlc.arg('unread_only')::boolean OR read_at IS NULL)
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+  AND NOT hidden_by_relationship(notifications.user_id, notifications.actor_id)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListAllNotificationsPage :many
+-- Unlike ListNotificationsPage, leaves out nothing; it backs the export.
+SELECT *
+FROM notifications
+WHERE user_id = sqlc.arg('user_id')
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*)
+FROM notifications
+WHERE user_id = $1
+  AND read_at IS NULL
+  AND NOT hidden_by_relationship(notifications.user_id, notifications.actor_id);
+
+-- name: MarkNotificationsRead :execrows
+-- A NULL ids list marks every unread notification of the user as read.
+UPDATE notifications
+SET read_at = NOW()
+WHERE user_id = sqlc.arg('user_id')
+  AND read_at IS NULL
+  AND (sqlc.narg('ids')::uuid[] IS NULL OR id = ANY(sqlc.narg('ids')::uuid[]));
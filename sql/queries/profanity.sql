@@ -0,0 +1,34 @@
+-- name: ListProfanityWords :many
+SELECT word
+FROM profanity_words
+ORDER BY word;
+
+-- name: CreateProfanityWord :execrows
+INSERT INTO profanity_words (word, created_at)
+VALUES ($1, NOW())
+ON CONFLICT (word) DO NOTHING;
+
+-- name: DeleteProfanityWord :execrows
+DELETE FROM profanity_words
+WHERE word = $1;
+
+-- name: UpsertChirpProfanityFlag :exec
+INSERT INTO chirp_profanity_flags (chirp_id, words, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (chirp_id) DO UPDATE
+SET words = EXCLUDED.words;
+
+-- name: DeleteChirpProfanityFlag :execrows
+DELETE FROM chirp_profanity_flags
+WHERE chirp_id = $1;
+
+-- name: ListChirpProfanityFlagsPage :many
+SELECT sqlc.embed(chirps), chirp_profanity_flags.words, chirp_profanity_flags.created_at AS flagged_at
+FROM chirp_profanity_flags
+JOIN chirps ON chirps.id = chirp_profanity_flags.chirp_id
+WHERE (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (chirp_profanity_flags.created_at, chirp_profanity_flags.chirp_id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY chirp_profanity_flags.created_at, chirp_profanity_flags.chirp_id
+LIMIT sqlc.arg('limit');
@@ -1,20 +1,119 @@
 -- name: CreateRefreshToken :one
-INSERT INTO refresh_tokens (token, user_id, expires_at, revoked_at)
-VALUES ($1, $2, $3, NULL)
+INSERT INTO refresh_tokens (token_hash, user_id, expires_at, revoked_at, family_id, user_agent, ip_address)
+VALUES ($1, $2, $3, NULL, $4, $5, $6)
 RETURNING *;
 
 -- name: GetRefreshToken :one
 SELECT * FROM refresh_tokens
-WHERE token = $1;
+WHERE token_hash = $1;
+
+-- name: RehashLegacyRefreshToken :exec
+-- Moves a token stored in the clear from before tokens were hashed over to
+-- its hash. It does nothing for any other token.
+UPDATE refresh_tokens
+SET token_hash = sqlc.arg('token_hash')
+WHERE token_hash = 'legacy:' || sqlc.arg('token')::text;
+
+-- name: GetRefreshTokenForUpdate :one
+-- Locks the token, so that concurrent refreshes with it take turns.
+SELECT * FROM refresh_tokens
+WHERE token_hash = $1
+FOR UPDATE;
 
 -- name: RevokeRefreshToken :exec
+-- Revokes the token along with every other token in its family, so logging
+-- out also retires any rotated copies.
+UPDATE refresh_tokens
+SET revoked_at = NOW(),
+    updated_at = NOW()
+WHERE family_id = (SELECT family_id FROM refresh_tokens AS t WHERE t.token_hash = $1)
+  AND revoked_at IS NULL;
+
+-- name: RotateRefreshToken :exec
+-- Retires a token that is being exchanged for a new one.
+UPDATE refresh_tokens
+SET rotated_at = NOW(),
+    revoked_at = NOW(),
+    updated_at = NOW()
+WHERE token_hash = $1
+  AND rotated_at IS NULL
+  AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokenFamily :exec
 UPDATE refresh_tokens
 SET revoked_at = NOW(),
     updated_at = NOW()
-WHERE token = $1;
+WHERE family_id = $1
+  AND revoked_at IS NULL;
 
 -- name: GetUserFromRefreshToken :one
 SELECT users.*
 FROM users
 JOIN refresh_tokens ON refresh_tokens.user_id = users.id
-WHERE refresh_tokens.token = $1;
+WHERE refresh_tokens.token_hash = $1;
+
+-- name: ListRefreshTokensByUser :many
+SELECT * FROM refresh_tokens
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: RevokeUserRefreshTokens :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW(),
+    updated_at = NOW()
+WHERE user_id = $1
+  AND revoked_at IS NULL;
+
+-- name: ListUserSessions :many
+-- One row per live session. Tokens are rotated on every refresh, so a session
+-- started when its family's first token was issued and was last used when its
+-- newest live token was; a sibling issued within the reuse grace window
+-- doesn't make it a second session.
+SELECT family_id, started_at, last_used_at, user_agent, ip_address, expires_at
+FROM (
+    SELECT DISTINCT ON (refresh_tokens.family_id)
+        refresh_tokens.family_id,
+        (
+            SELECT MIN(f.created_at)
+            FROM refresh_tokens AS f
+            WHERE f.family_id = refresh_tokens.family_id
+        )::timestamp AS started_at,
+        refresh_tokens.created_at AS last_used_at,
+        refresh_tokens.user_agent,
+        refresh_tokens.ip_address,
+        refresh_tokens.expires_at
+    FROM refresh_tokens
+    WHERE refresh_tokens.user_id = $1
+      AND refresh_tokens.revoked_at IS NULL
+      AND refresh_tokens.expires_at > $2
+    ORDER BY refresh_tokens.family_id, refresh_tokens.created_at DESC
+) AS sessions
+ORDER BY last_used_at DESC;
+
+-- name: RevokeUserSession :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW(),
+    updated_at = NOW()
+WHERE family_id = $1
+  AND user_id = $2
+  AND revoked_at IS NULL;
+
+-- name: RevokeOtherUserSessions :many
+-- Revokes all of a user's sessions except keep_family_id, returning the
+-- sessions it ended.
+UPDATE refresh_tokens
+SET revoked_at = NOW(),
+    updated_at = NOW()
+WHERE user_id = sqlc.arg('user_id')
+  AND family_id <> sqlc.arg('keep_family_id')
+  AND revoked_at IS NULL
+RETURNING family_id;
+
+-- name: IsSessionActive :one
+-- A session stays active while its family still has an unrevoked token.
+SELECT EXISTS (
+    SELECT 1
+    FROM refresh_tokens
+    WHERE family_id = $1
+      AND revoked_at IS NULL
+);
@@ -0,0 +1,20 @@
+-- name: GetUserFilters :one
+SELECT *
+FROM user_filters
+WHERE user_id = $1;
+
+-- name: UpsertUserFilters :one
+INSERT INTO user_filters (user_id, muted_words, muted_hashtags, muted_user_ids, action, updated_at)
+VALUES ($1, $2, $3, $4, $5, NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET muted_words = EXCLUDED.muted_words,
+    muted_hashtags = EXCLUDED.muted_hashtags,
+    muted_user_ids = EXCLUDED.muted_user_ids,
+    action = EXCLUDED.action,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: ListCollapsedChirpIDs :many
+SELECT id
+FROM unnest(sqlc.arg('chirp_ids')::uuid[]) AS id
+WHERE chirp_filter_action(sqlc.arg('viewer_id')::uuid, id) = 'collapse';
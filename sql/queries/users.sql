@@ -1,14 +1,18 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password, is_chirpy_red)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_url)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
     $2,
-    FALSE
+    FALSE,
+    $3,
+    $4,
+    $5,
+    $6
 )
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red;
+RETURNING *;
 
 
 -- name: DeletAllUsers :exec
@@ -16,16 +20,17 @@ DELETE FROM users;
 
 
 -- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red
+SELECT *
 FROM users
 WHERE email = $1
 LIMIT 1;
 
--- name: UpdateUser :one
+-- name: ConfirmUserEmail :one
+-- Switches to a verified address; the old one, if different, is dropped.
 UPDATE users
 SET
     email = $2,
-    hashed_password = $3,
+    email_verified_at = NOW(),
     updated_at = NOW()
 WHERE id = $1
 RETURNING *;
@@ -44,3 +49,97 @@ WHERE id = $1;
 
 -- name: DeleteAllUsers :exec
 DELETE FROM users;
+
+-- name: ListUsersByUsernames :many
+SELECT *
+FROM users
+WHERE LOWER(username) = ANY(sqlc.arg('usernames')::text[]);
+
+-- name: UpdateUserProfile :one
+UPDATE users
+SET
+    username = $2,
+    display_name = $3,
+    bio = $4,
+    avatar_url = $5,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetUserByUsername :one
+SELECT *
+FROM users
+WHERE LOWER(username) = LOWER(sqlc.arg('username'));
+
+-- name: ListUsersByIDs :many
+SELECT *
+FROM users
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: SetUserRole :one
+UPDATE users
+SET
+    role = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: PromoteFirstAdmin :one
+-- Makes a verified user admin, but only while there is no admin at all.
+UPDATE users
+SET
+    role = 'admin',
+    updated_at = NOW()
+WHERE id = $1
+  AND email_verified_at IS NOT NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users WHERE role = 'admin'
+  )
+RETURNING *;
+
+-- name: SetUserStatus :execrows
+-- Moves a user from one status to another, doing nothing if they have moved on.
+UPDATE users
+SET
+    status = sqlc.arg('status'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+  AND status = sqlc.arg('from_status');
+
+-- name: ScheduleUserDeletion :execrows
+UPDATE users
+SET
+    status = 'deleted',
+    delete_after = $2,
+    updated_at = NOW()
+WHERE id = $1
+  AND status = 'active';
+
+-- name: CancelUserDeletion :execrows
+UPDATE users
+SET
+    status = 'active',
+    delete_after = NULL,
+    updated_at = NOW()
+WHERE id = $1
+  AND status = 'deleted';
+
+-- name: DeleteUsersPastGracePeriod :execrows
+-- Everything the users owned goes with them through ON DELETE CASCADE.
+DELETE FROM users
+WHERE status = 'deleted'
+  AND delete_after <= $1;
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET
+    hashed_password = $2,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: BumpUserTokenVersion :exec
+UPDATE users
+SET
+    token_version = token_version + 1,
+    updated_at = NOW()
+WHERE id = $1;
@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN original_id UUID REFERENCES chirps(id) ON DELETE SET NULL,
+ADD COLUMN repost_kind TEXT CHECK (repost_kind IN ('rechirp', 'quote'));
+
+-- A user can plainly rechirp a given chirp only once
+CREATE UNIQUE INDEX chirps_one_rechirp_per_user_idx
+    ON chirps (user_id, original_id)
+    WHERE repost_kind = 'rechirp';
+
+-- +goose Down
+DROP INDEX chirps_one_rechirp_per_user_idx;
+
+ALTER TABLE chirps
+DROP COLUMN repost_kind,
+DROP COLUMN original_id;
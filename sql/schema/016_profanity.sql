@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE profanity_words (
+    word TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- The words the filter used to hard-code
+INSERT INTO profanity_words (word, created_at)
+VALUES ('kerfuffle', NOW()), ('sharbert', NOW()), ('fornax', NOW());
+
+CREATE TABLE chirp_profanity_flags (
+    chirp_id UUID PRIMARY KEY REFERENCES chirps(id) ON DELETE CASCADE,
+    words TEXT[] NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX chirp_profanity_flags_created_at_idx ON chirp_profanity_flags (created_at, chirp_id);
+
+-- +goose Down
+DROP TABLE chirp_profanity_flags;
+DROP TABLE profanity_words;
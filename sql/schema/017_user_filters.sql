@@ -0,0 +1,40 @@
+-- +goose Up
+CREATE TABLE user_filters (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    muted_words TEXT[] NOT NULL DEFAULT '{}',
+    muted_hashtags TEXT[] NOT NULL DEFAULT '{}',
+    muted_user_ids UUID[] NOT NULL DEFAULT '{}',
+    action TEXT NOT NULL DEFAULT 'hide' CHECK (action IN ('hide', 'collapse')),
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- chirp_filter_action is what the viewer's filters do to a chirp: 'hide',
+-- 'collapse', or NULL when nothing matches. A rechirp or quote also matches
+-- through its original. Muted words match whole words of the body, ignoring
+-- case. List queries call it before their LIMIT so hidden chirps don't leave
+-- pages short.
+-- +goose StatementBegin
+CREATE FUNCTION chirp_filter_action(viewer UUID, chirp UUID) RETURNS TEXT AS $$
+    SELECT f.action
+    FROM user_filters f
+    JOIN chirps c ON c.id = chirp OR c.id = (SELECT original_id FROM chirps WHERE id = chirp)
+    WHERE f.user_id = viewer
+      AND (
+        c.user_id = ANY (f.muted_user_ids)
+        OR f.muted_words && regexp_split_to_array(lower(c.body), '[^[:alnum:]]+')
+        OR EXISTS (
+            SELECT 1
+            FROM chirp_hashtags
+            JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+            WHERE chirp_hashtags.chirp_id = c.id
+              AND hashtags.tag = ANY (f.muted_hashtags)
+        )
+      )
+    LIMIT 1
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION chirp_filter_action(UUID, UUID);
+
+DROP TABLE user_filters;
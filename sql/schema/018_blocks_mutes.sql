@@ -0,0 +1,44 @@
+-- +goose Up
+CREATE TABLE user_blocks (
+    blocker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id)
+);
+
+-- Visibility checks look blocks up from the blocked side too
+CREATE INDEX user_blocks_blocked_id_idx ON user_blocks (blocked_id);
+
+CREATE TABLE user_mutes (
+    muter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    muted_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (muter_id, muted_id),
+    CHECK (muter_id <> muted_id)
+);
+
+-- hidden_by_relationship reports whether what author writes is kept from
+-- viewer: a block in either direction, or viewer muting author. It is false
+-- for a NULL viewer, so anonymous reads see everything.
+-- +goose StatementBegin
+CREATE FUNCTION hidden_by_relationship(viewer UUID, author UUID) RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1
+        FROM user_blocks
+        WHERE (blocker_id = author AND blocked_id = viewer)
+           OR (blocker_id = viewer AND blocked_id = author)
+    ) OR EXISTS (
+        SELECT 1
+        FROM user_mutes
+        WHERE muter_id = viewer
+          AND muted_id = author
+    )
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION hidden_by_relationship(UUID, UUID);
+
+DROP TABLE user_mutes;
+DROP TABLE user_blocks;
@@ -0,0 +1,40 @@
+-- +goose Up
+-- Hidden chirps stay in the database for the record but drop out of every read
+ALTER TABLE chirps ADD COLUMN hidden_at TIMESTAMP;
+
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP;
+
+CREATE TABLE chirp_reports (
+    id UUID PRIMARY KEY,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL CHECK (reason IN ('spam', 'harassment', 'hate', 'violence', 'sexual', 'misinformation', 'other')),
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP,
+    resolution TEXT CHECK (resolution IN ('dismiss', 'hide', 'suspend'))
+);
+
+-- One open report per chirp and reporter
+CREATE UNIQUE INDEX chirp_reports_open_idx ON chirp_reports (chirp_id, reporter_id) WHERE resolved_at IS NULL;
+
+-- Moderation log; rows outlive the chirps and users they mention
+CREATE TABLE moderation_actions (
+    id UUID PRIMARY KEY,
+    chirp_id UUID REFERENCES chirps(id) ON DELETE SET NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    -- The moderator who took the action; NULL once their account is erased
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action TEXT NOT NULL CHECK (action IN ('dismiss', 'hide', 'suspend')),
+    note TEXT NOT NULL DEFAULT '',
+    reports_resolved INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX moderation_actions_created_at_idx ON moderation_actions (created_at, id);
+
+-- +goose Down
+DROP TABLE moderation_actions;
+DROP TABLE chirp_reports;
+ALTER TABLE users DROP COLUMN suspended_at;
+ALTER TABLE chirps DROP COLUMN hidden_at;
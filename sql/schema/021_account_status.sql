@@ -0,0 +1,35 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'deactivated', 'suspended', 'deleted')),
+    ADD COLUMN suspension_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN suspended_until TIMESTAMP;
+
+-- status takes over from suspended_at
+UPDATE users SET status = 'suspended' WHERE suspended_at IS NOT NULL;
+ALTER TABLE users DROP COLUMN suspended_at;
+
+-- Users whose chirps are hidden from reads. A suspension with an expiry
+-- lapses on its own, without anything having to flip the status back.
+CREATE VIEW inactive_users AS
+SELECT id
+FROM users
+WHERE status <> 'active'
+  AND NOT (status = 'suspended' AND suspended_until IS NOT NULL AND suspended_until <= LOCALTIMESTAMP);
+
+-- Admins can lift suspensions made outside the report queue
+ALTER TABLE moderation_actions DROP CONSTRAINT moderation_actions_action_check;
+ALTER TABLE moderation_actions ADD CONSTRAINT moderation_actions_action_check
+    CHECK (action IN ('dismiss', 'hide', 'suspend', 'set_role', 'unsuspend'));
+
+-- +goose Down
+DELETE FROM moderation_actions WHERE action = 'unsuspend';
+ALTER TABLE moderation_actions DROP CONSTRAINT moderation_actions_action_check;
+ALTER TABLE moderation_actions ADD CONSTRAINT moderation_actions_action_check
+    CHECK (action IN ('dismiss', 'hide', 'suspend', 'set_role'));
+DROP VIEW inactive_users;
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP;
+UPDATE users SET suspended_at = updated_at WHERE status = 'suspended';
+ALTER TABLE users
+    DROP COLUMN suspended_until,
+    DROP COLUMN suspension_reason,
+    DROP COLUMN status;
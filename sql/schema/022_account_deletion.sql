@@ -0,0 +1,9 @@
+-- +goose Up
+-- When a deleted account's grace period runs out and its row can be removed
+ALTER TABLE users ADD COLUMN delete_after TIMESTAMP;
+
+CREATE INDEX users_delete_after_idx ON users (delete_after) WHERE status = 'deleted';
+
+-- +goose Down
+DROP INDEX users_delete_after_idx;
+ALTER TABLE users DROP COLUMN delete_after;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Existing accounts start out unverified like new ones
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+
+-- A token proves the user can read mail sent to email, which is either their
+-- current address or one they want to change to.
+CREATE TABLE email_verification_tokens (
+    token_hash TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP
+);
+
+CREATE INDEX email_verification_tokens_user_id_idx ON email_verification_tokens (user_id);
+
+-- +goose Down
+DROP TABLE email_verification_tokens;
+ALTER TABLE users DROP COLUMN email_verified_at;
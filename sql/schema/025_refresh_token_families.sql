@@ -0,0 +1,18 @@
+-- +goose Up
+-- Every refresh hands out a new token in the same family as the old one.
+-- Presenting a token that was already rotated means someone kept a copy, so
+-- the whole family is revoked, unless it comes back within a few seconds of
+-- its rotation, as it does when a client sends concurrent refreshes.
+ALTER TABLE refresh_tokens ADD COLUMN family_id UUID;
+ALTER TABLE refresh_tokens ADD COLUMN rotated_at TIMESTAMP;
+
+-- Existing tokens each start a family of their own
+UPDATE refresh_tokens SET family_id = gen_random_uuid();
+ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX refresh_tokens_family_id_idx ON refresh_tokens (family_id);
+
+-- +goose Down
+DROP INDEX refresh_tokens_family_id_idx;
+ALTER TABLE refresh_tokens DROP COLUMN rotated_at;
+ALTER TABLE refresh_tokens DROP COLUMN family_id;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Refresh tokens are kept as an HMAC-SHA256 of the token keyed with
+-- REFRESH_TOKEN_SECRET. Tokens issued before that are still in the clear;
+-- they get a "legacy:" prefix, which no hash has, and the server swaps each
+-- one for its hash the first time it is presented.
+ALTER TABLE refresh_tokens RENAME COLUMN token TO token_hash;
+UPDATE refresh_tokens SET token_hash = 'legacy:' || token_hash;
+
+-- +goose Down
+-- Hashes can't be turned back into tokens, so only sessions whose token was
+-- never used since the upgrade survive
+DELETE FROM refresh_tokens WHERE token_hash NOT LIKE 'legacy:%';
+UPDATE refresh_tokens SET token_hash = substr(token_hash, length('legacy:') + 1);
+ALTER TABLE refresh_tokens RENAME COLUMN token_hash TO token;
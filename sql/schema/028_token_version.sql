@@ -0,0 +1,7 @@
+-- +goose Up
+-- Access tokens carry the version current when they were issued; bumping it
+-- revokes every access token the user holds.
+ALTER TABLE users ADD COLUMN token_version INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN token_version;
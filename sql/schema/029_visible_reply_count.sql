@@ -0,0 +1,17 @@
+-- +goose Up
+-- visible_reply_count counts the direct replies to chirp that viewer can
+-- read, leaving out the same replies thread listings do.
+-- +goose StatementBegin
+CREATE FUNCTION visible_reply_count(viewer UUID, chirp UUID) RETURNS BIGINT AS $$
+    SELECT COUNT(*)
+    FROM chirps r
+    WHERE r.parent_id = chirp
+      AND r.hidden_at IS NULL
+      AND r.user_id NOT IN (SELECT id FROM inactive_users)
+      AND chirp_filter_action(viewer, r.id) IS DISTINCT FROM 'hide'
+      AND NOT hidden_by_relationship(viewer, r.user_id)
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION visible_reply_count(UUID, UUID);
@@ -1,20 +1,27 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/SaadVSP96/Chirpy_Server.git/internal/auth"
 	"github.com/SaadVSP96/Chirpy_Server.git/internal/database"
+	"github.com/SaadVSP96/Chirpy_Server.git/internal/mail"
 	"github.com/google/uuid"
 )
 
 type CreateUserRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email       string `json:"email"`
+	Password    string `json:"password"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	AvatarURL   string `json:"avatar_url"`
 }
 
 type CreateUserResponse struct {
@@ -23,6 +30,38 @@ type CreateUserResponse struct {
 	UpdatedAt   string `json:"updated_at"`
 	Email       string `json:"email"`
 	IsChirpyRed bool   `json:"is_chirpy_red"`
+	Username    string `json:"username,omitempty"`
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	AvatarURL   string `json:"avatar_url"`
+	Role        string `json:"role"`
+	// EmailVerified is whether Email has been confirmed; PendingEmail is an
+	// address the user is changing to that hasn't been confirmed yet.
+	EmailVerified bool   `json:"email_verified"`
+	PendingEmail  string `json:"pending_email,omitempty"`
+	// Token and RefreshToken start a new session after a password change,
+	// which signs out every other one.
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// userResponse converts a database user into the private representation that
+// is only ever sent to the account owner.
+func userResponse(user database.User) CreateUserResponse {
+	return CreateUserResponse{
+		ID:          user.ID.String(),
+		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   user.UpdatedAt.Format(time.RFC3339),
+		Email:       user.Email,
+		IsChirpyRed: user.IsChirpyRed,
+		Username:    user.Username.String,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarUrl,
+		Role:        user.Role,
+
+		EmailVerified: user.EmailVerifiedAt.Valid,
+	}
 }
 
 type LoginRequest struct {
@@ -39,6 +78,7 @@ type LoginResponse struct {
 	Token        string `json:"token"`
 	RefreshToken string `json:"refresh_token"`
 	IsChirpyRed  bool   `json:"is_chirpy_red"`
+	Role         string `json:"role"`
 }
 
 type PolkaWebhookRequest struct {
@@ -56,44 +96,163 @@ func (cfg *apiConfig) handlerUpdateUser(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	// Validate JWT bearer token
-	userID, err := auth.ValidateJWT(tokenString, cfg.jwtSecret)
+	userID, err := auth.ValidateJWT(tokenString, cfg.jwtKeys)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
 		return
 	}
-	// Parse Body
+	// Parse Body; profile fields left out of the body keep their current value
 	var req struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Email       string  `json:"email"`
+		Password    string  `json:"password"`
+		Username    *string `json:"username"`
+		DisplayName *string `json:"display_name"`
+		Bio         *string `json:"bio"`
+		AvatarURL   *string `json:"avatar_url"`
 	}
 	json.NewDecoder(r.Body).Decode(&req)
-	if req.Email == "" || req.Password == "" {
+	updateProfile := req.Username != nil || req.DisplayName != nil || req.Bio != nil || req.AvatarURL != nil
+	updateCredentials := req.Email != "" || req.Password != "" || !updateProfile
+	if updateCredentials && (req.Email == "" || req.Password == "") {
 		respondWithError(w, http.StatusBadRequest, "Email and password required", nil)
 		return
 	}
-	// hash new password
-	hashed, err := auth.HashPassword(req.Password)
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
 	if err != nil {
-		respondWithError(w, 500, "Failed To Hash", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
 	}
-	// run SQL update
-	user, err := cfg.dbQueries.UpdateUser(r.Context(), database.UpdateUserParams{
-		ID:             userID,
-		Email:          req.Email,
-		HashedPassword: hashed,
-	})
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	user, err := qtx.GetUserByID(r.Context(), userID)
 	if err != nil {
 		respondWithError(w, 500, "Update failed", err)
 		return
 	}
+
+	// A new address only replaces the old one once the user confirms it
+	var pendingEmail string
+	var verification mail.Message
+	var accessToken, refreshToken string
+	if updateCredentials {
+		email, err := mail.ValidateAddress(req.Email)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		// hash new password
+		hashed, err := auth.HashPassword(req.Password)
+		if err != nil {
+			respondWithError(w, 500, "Failed To Hash", err)
+			return
+		}
+		// run SQL update
+		err = qtx.UpdateUserPassword(r.Context(), database.UpdateUserPasswordParams{
+			ID:             userID,
+			HashedPassword: hashed,
+		})
+		if err != nil {
+			respondWithError(w, 500, "Update failed", err)
+			return
+		}
+		// Every session signs out, access tokens included; the caller
+		// gets a fresh one below
+		if err := qtx.RevokeUserRefreshTokens(r.Context(), userID); err != nil {
+			respondWithError(w, 500, "Update failed", err)
+			return
+		}
+		if err := qtx.BumpUserTokenVersion(r.Context(), userID); err != nil {
+			respondWithError(w, 500, "Update failed", err)
+			return
+		}
+		if user, err = qtx.GetUserByID(r.Context(), userID); err != nil {
+			respondWithError(w, 500, "Update failed", err)
+			return
+		}
+		sessionID := uuid.New()
+		if accessToken, err = auth.MakeJWT(user.ID, auth.Role(user.Role), sessionID, user.TokenVersion, cfg.jwtKeys, time.Hour); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to create JWT", err)
+			return
+		}
+		if refreshToken, err = cfg.issueRefreshToken(r, qtx, userID, sessionID); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Could not save refresh token", err)
+			return
+		}
+
+		if email != user.Email {
+			_, err := qtx.GetUserByEmail(r.Context(), email)
+			if err == nil {
+				respondWithError(w, http.StatusConflict, "Email is already in use", nil)
+				return
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, 500, "Update failed", err)
+				return
+			}
+			verification, err = cfg.issueEmailVerification(r.Context(), qtx, userID, email)
+			if err != nil {
+				respondWithError(w, http.StatusInternalServerError, "Couldn't create verification token", err)
+				return
+			}
+			pendingEmail = email
+		}
+	}
+
+	if updateProfile {
+		params := database.UpdateUserProfileParams{
+			ID:          user.ID,
+			Username:    user.Username,
+			DisplayName: user.DisplayName,
+			Bio:         user.Bio,
+			AvatarUrl:   user.AvatarUrl,
+		}
+		if req.Username != nil {
+			// An empty username gives the handle up
+			params.Username = sql.NullString{String: *req.Username, Valid: *req.Username != ""}
+		}
+		if req.DisplayName != nil {
+			params.DisplayName = *req.DisplayName
+		}
+		if req.Bio != nil {
+			params.Bio = *req.Bio
+		}
+		if req.AvatarURL != nil {
+			params.AvatarUrl = *req.AvatarURL
+		}
+		if err := validateProfile(params.Username.String, params.DisplayName, params.Bio, params.AvatarUrl); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+
+		user, err = qtx.UpdateUserProfile(r.Context(), params)
+		if err != nil {
+			if isUniqueViolation(err, usernameIndex) {
+				respondWithError(w, http.StatusConflict, "Username is already taken", nil)
+				return
+			}
+			respondWithError(w, 500, "Update failed", err)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, 500, "Update failed", err)
+		return
+	}
+	if updateCredentials {
+		cfg.authUsers.Forget(userID)
+	}
+	if pendingEmail != "" {
+		cfg.sendMail(verification)
+	}
 	// Respond without password
-	respondWithJSON(w, 200, CreateUserResponse{
-		ID:          user.ID.String(),
-		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   user.UpdatedAt.Format(time.RFC3339),
-		Email:       user.Email,
-		IsChirpyRed: user.IsChirpyRed,
-	})
+	resp := userResponse(user)
+	resp.PendingEmail = pendingEmail
+	resp.Token = accessToken
+	resp.RefreshToken = refreshToken
+	respondWithJSON(w, 200, resp)
 
 }
 
@@ -111,33 +270,67 @@ func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, r *http.Request)
 		respondWithError(w, http.StatusBadRequest, "Email and password are required", nil)
 		return
 	}
+	req.Email, err = mail.ValidateAddress(req.Email)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if err := validateProfile(req.Username, req.DisplayName, req.Bio, req.AvatarURL); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
 	// hash the password
 	hashedPassword, err := auth.HashPassword(req.Password)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to hash password", err)
 		return
 	}
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
 	// run the SQLC generated query function
-	user, err := cfg.dbQueries.CreateUser(
+	user, err := qtx.CreateUser(
 		r.Context(),
 		database.CreateUserParams{
 			Email:          req.Email,
 			HashedPassword: hashedPassword,
+			Username:       sql.NullString{String: req.Username, Valid: req.Username != ""},
+			DisplayName:    req.DisplayName,
+			Bio:            req.Bio,
+			AvatarUrl:      req.AvatarURL,
 		},
 	)
 	if err != nil {
+		if isUniqueViolation(err, usernameIndex) {
+			respondWithError(w, http.StatusConflict, "Username is already taken", nil)
+			return
+		}
+		if isUniqueViolation(err, emailIndex) {
+			respondWithError(w, http.StatusConflict, "Email is already in use", nil)
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "Could not create user", err)
 		return
 	}
 
+	verification, err := cfg.issueEmailVerification(r.Context(), qtx, user.ID, user.Email)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create verification token", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not create user", err)
+		return
+	}
+	cfg.sendMail(verification)
+
 	// build a response to send back in the response to post request
-	respondWithJSON(w, http.StatusCreated, CreateUserResponse{
-		ID:          user.ID.String(),
-		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   user.UpdatedAt.Format(time.RFC3339),
-		Email:       user.Email,
-		IsChirpyRed: user.IsChirpyRed,
-	})
+	respondWithJSON(w, http.StatusCreated, userResponse(user))
 }
 
 func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
@@ -165,27 +358,28 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create access token (JWT)
-	accessToken, err := auth.MakeJWT(user.ID, cfg.jwtSecret, time.Hour)
+	if err := checkAccount(user); err != nil {
+		respondWithError(w, http.StatusForbidden, err.Error(), err)
+		return
+	}
+
+	user, err = cfg.bootstrapAdmin(r.Context(), user)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to create JWT", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to bootstrap admin", err)
 		return
 	}
 
-	// Create refresh token (random string)
-	refreshToken, err := auth.MakeRefreshToken()
+	// Each login starts a new session, which is a new family of refresh tokens
+	sessionID := uuid.New()
+
+	// Create access token (JWT)
+	accessToken, err := auth.MakeJWT(user.ID, auth.Role(user.Role), sessionID, user.TokenVersion, cfg.jwtKeys, time.Hour)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to create refresh token", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create JWT", err)
 		return
 	}
 
-	// Save refresh token to DB
-	expiresAt := time.Now().UTC().Add(60 * 24 * time.Hour) // 60 days
-	_, err = cfg.dbQueries.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{
-		Token:     refreshToken,
-		UserID:    user.ID,
-		ExpiresAt: expiresAt,
-	})
+	refreshToken, err := cfg.issueRefreshToken(r, cfg.dbQueries, user.ID, sessionID)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Could not save refresh token", err)
 		return
@@ -200,6 +394,7 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 		Token:        accessToken,
 		RefreshToken: refreshToken,
 		IsChirpyRed:  user.IsChirpyRed,
+		Role:         user.Role,
 	}
 
 	respondWithJSON(w, http.StatusOK, resp)
@@ -232,41 +427,144 @@ func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start transaction", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+
+	tokenHash, err := cfg.refreshTokenHash(r.Context(), qtx, refreshTokenString)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't look up refresh token", err)
+		return
+	}
+
 	// Get refresh token from database
-	refreshToken, err := cfg.dbQueries.GetRefreshToken(r.Context(), refreshTokenString)
+	refreshToken, err := qtx.GetRefreshTokenForUpdate(r.Context(), tokenHash)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Invalid token", nil)
 		return
 	}
 
+	// A rotated token should never come back, except straight after its
+	// rotation when the client sent concurrent refreshes and this one lost
+	// the race. Any later, whoever presents it, the family can no longer be
+	// trusted.
+	rotated := refreshToken.RotatedAt.Valid
+	if rotated {
+		active, err := qtx.IsSessionActive(r.Context(), refreshToken.FamilyID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't check session", err)
+			return
+		}
+		if !active {
+			respondWithError(w, http.StatusUnauthorized, "Revoked token", nil)
+			return
+		}
+		if time.Now().UTC().Sub(refreshToken.RotatedAt.Time) > cfg.refreshReuseGrace {
+			tx.Rollback()
+			cfg.revokeRefreshFamily(r.Context(), refreshToken)
+			respondWithError(w, http.StatusUnauthorized, "Refresh token reuse detected", nil)
+			return
+		}
+	}
 	if time.Now().UTC().After(refreshToken.ExpiresAt) {
 		respondWithError(w, http.StatusUnauthorized, "Expired token", nil)
 		return
 	}
-	if refreshToken.RevokedAt.Valid && time.Now().UTC().After(refreshToken.RevokedAt.Time) {
+	if !rotated && refreshToken.RevokedAt.Valid && time.Now().UTC().After(refreshToken.RevokedAt.Time) {
 		respondWithError(w, http.StatusUnauthorized, "Revoked token", nil)
 		return
 	}
 
-	user, err := cfg.dbQueries.GetUserFromRefreshToken(r.Context(), refreshToken.Token)
+	user, err := qtx.GetUserFromRefreshToken(r.Context(), refreshToken.TokenHash)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Invalid token", nil)
 		return
 	}
+	if err := checkAccount(user); err != nil {
+		respondWithError(w, http.StatusForbidden, err.Error(), err)
+		return
+	}
 
 	// Create new access token
-	newAccessToken, err := auth.MakeJWT(user.ID, cfg.jwtSecret, time.Hour)
+	newAccessToken, err := auth.MakeJWT(user.ID, auth.Role(user.Role), refreshToken.FamilyID, user.TokenVersion, cfg.jwtKeys, time.Hour)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to create token", err)
 		return
 	}
 
+	// Swap the refresh token for a new one in the same family. A token
+	// replayed within the grace window was rotated already and just gets a
+	// sibling.
+	if !rotated {
+		if err := qtx.RotateRefreshToken(r.Context(), refreshToken.TokenHash); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to rotate refresh token", err)
+			return
+		}
+	}
+	newRefreshToken, err := cfg.issueRefreshToken(r, qtx, user.ID, refreshToken.FamilyID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not save refresh token", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to rotate refresh token", err)
+		return
+	}
+
 	respondWithJSON(w, http.StatusOK, map[string]string{
-		"token": newAccessToken,
+		"token":         newAccessToken,
+		"refresh_token": newRefreshToken,
 	})
 
 }
 
+// issueRefreshToken creates a refresh token for the user in the given family,
+// valid for 60 days, noting the client r came from. Only its hash is stored.
+func (cfg *apiConfig) issueRefreshToken(r *http.Request, q *database.Queries, userID, familyID uuid.UUID) (string, error) {
+	token, err := auth.MakeRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	_, err = q.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{
+		TokenHash: auth.HashRefreshToken(token, cfg.refreshSecret),
+		UserID:    userID,
+		ExpiresAt: time.Now().UTC().Add(60 * 24 * time.Hour),
+		FamilyID:  familyID,
+		UserAgent: truncate(r.UserAgent(), maxUserAgentLength),
+		IpAddress: clientIP(r),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// refreshTokenHash returns the hash token is stored under. A token issued
+// before refresh tokens were hashed is moved over to its hash on the way, so
+// sessions from before the upgrade carry on.
+func (cfg *apiConfig) refreshTokenHash(ctx context.Context, q *database.Queries, token string) (string, error) {
+	hash := auth.HashRefreshToken(token, cfg.refreshSecret)
+	err := q.RehashLegacyRefreshToken(ctx, database.RehashLegacyRefreshTokenParams{
+		TokenHash: hash,
+		Token:     token,
+	})
+	return hash, err
+}
+
+// revokeRefreshFamily revokes every token descended from the same login as t
+// after it turned up again following rotation.
+func (cfg *apiConfig) revokeRefreshFamily(ctx context.Context, t database.RefreshToken) {
+	log.Printf("Refresh token reuse for user %s; revoking family %s", t.UserID, t.FamilyID)
+	if err := cfg.dbQueries.RevokeRefreshTokenFamily(ctx, t.FamilyID); err != nil {
+		log.Printf("Failed to revoke refresh token family %s: %v", t.FamilyID, err)
+	}
+	cfg.authSessions.Forget(t.FamilyID)
+}
+
 func (cfg *apiConfig) handlerRevoke(w http.ResponseWriter, r *http.Request) {
 	tokenString, err := auth.GetBearerToken(r.Header)
 	if err != nil {
@@ -274,11 +572,20 @@ func (cfg *apiConfig) handlerRevoke(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = cfg.dbQueries.RevokeRefreshToken(r.Context(), tokenString)
+	tokenHash, err := cfg.refreshTokenHash(r.Context(), cfg.dbQueries, tokenString)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't look up refresh token", err)
+		return
+	}
+	err = cfg.dbQueries.RevokeRefreshToken(r.Context(), tokenHash)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to revoke token", err)
 		return
 	}
+	// Access tokens from the session stop working too
+	if refreshToken, err := cfg.dbQueries.GetRefreshToken(r.Context(), tokenHash); err == nil {
+		cfg.authSessions.Forget(refreshToken.FamilyID)
+	}
 
 	w.WriteHeader(http.StatusNoContent)
 }